@@ -0,0 +1,111 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggageFieldsEnv is the environment variable LoadConfig reads the comma separated baggage fields from
+const BaggageFieldsEnv = "GO11Y_BAGGAGE_FIELDS"
+
+// SetBaggage adds the key and value to the W3C baggage of the context, replacing any value the key already has. The
+// baggage is sent to other services by PropagateRoundTripper, and LogRequest adds the members in their baggage fields
+// to the logs and spans of the requests they handle.
+func (o *Observer) SetBaggage(ctx context.Context, key, value string) (ctxWithBaggage context.Context, fault error) {
+	// the baggage accepts any key, but the W3C header only carries tokens, so the others would be dropped silently
+	if key == "" || strings.IndexFunc(key, func(r rune) bool { return !isTokenChar(r) }) != -1 {
+		return ctx, fmt.Errorf("could not set baggage '%s': the key must be a token, without spaces or separators", key)
+	}
+
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("could not set baggage '%s': %w", key, err)
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("could not set baggage '%s': %w", key, err)
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// isTokenChar returns true if the rune can be part of a token, as defined by RFC 7230
+func isTokenChar(r rune) bool {
+	return r < 0x7f && (r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || strings.ContainsRune("!#$%&'*+-.^_`|~", r))
+}
+
+// WithBaggageFields adds the baggage members whose keys match the fields (globs, as used by path.Match, so "flag.*"
+// matches "flag.checkout") to the log args and span attributes of the requests LogRequest handles. No baggage is added
+// unless there are fields, as the baggage comes from the callers.
+func WithBaggageFields(fields ...string) Option {
+	return func(s *settings) {
+		s.baggageFields = append([]string{}, fields...)
+	}
+}
+
+// validateBaggageFields returns an error for the first field that isn't a valid pattern
+func validateBaggageFields(fields []string) (fault error) {
+	for _, f := range fields {
+		if _, err := path.Match(f, ""); err != nil || f == "" {
+			return fmt.Errorf("invalid pattern '%s'", f)
+		}
+	}
+
+	return nil
+}
+
+// splitBaggageFields splits a comma separated list of baggage fields, dropping the empty ones
+func splitBaggageFields(s string) []string {
+	fields := []string{}
+
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// baggageArgs returns the key and value of each member of the baggage in the context that matches one of the fields,
+// sorted by key so the args are stable
+func baggageArgs(ctx context.Context, fields []string) []any {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	members := baggage.FromContext(ctx).Members()
+	sort.Slice(members, func(i, j int) bool { return members[i].Key() < members[j].Key() })
+
+	args := []any{}
+
+	for _, m := range members {
+		for _, f := range fields {
+			if ok, _ := path.Match(f, m.Key()); ok {
+				args = append(args, m.Key(), m.Value())
+				break
+			}
+		}
+	}
+
+	return args
+}
+
+// baggageFields returns the baggage fields of the live configuration
+func (o *Observer) baggageFields() []string {
+	if o == nil || o.live == nil {
+		return nil
+	}
+
+	if fields := o.live.baggage.Load(); fields != nil {
+		return *fields
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// sendWithBaggage sends a request with the baggage to a LogRequest server, and returns the last record it logged when a
+// request was received
+func sendWithBaggage(t *testing.T, o *go11y.Observer, buf *syncBuffer, members map[string]string) map[string]any {
+	t.Helper()
+
+	srv := httptest.NewServer(go11y.LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer srv.Close()
+
+	ctx := context.Background()
+	for k, v := range members {
+		var err error
+		if ctx, err = o.SetBaggage(ctx, k, v); err != nil {
+			t.Fatalf("failed to set baggage: %v", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/orders", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: go11y.PropagateRoundTripper(http.DefaultTransport)}).Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	var received map[string]any
+	for _, r := range buf.records(t) {
+		if r["msg"] == "request received" {
+			received = r
+		}
+	}
+
+	if received == nil {
+		t.Fatalf("expected the request to be logged")
+	}
+
+	return received
+}
+
+func TestSetBaggage(t *testing.T) {
+	_, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	ctx, err := o.SetBaggage(context.Background(), "tenant_id", "acme corp")
+	if err != nil {
+		t.Fatalf("failed to set baggage: %v", err)
+	}
+
+	ctx, err = o.SetBaggage(ctx, "tenant_id", "globex")
+	if err != nil {
+		t.Fatalf("failed to set baggage: %v", err)
+	}
+
+	if v := baggage.FromContext(ctx).Member("tenant_id").Value(); v != "globex" {
+		t.Errorf("expected the value to be replaced, got %q", v)
+	}
+
+	if _, err := o.SetBaggage(ctx, "tenant id", "acme"); err == nil || !strings.Contains(err.Error(), "could not set baggage 'tenant id'") {
+		t.Errorf("expected an error for the invalid key, got %v", err)
+	}
+}
+
+func TestBaggageFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fields   []string
+		expected map[string]string
+	}{
+		{
+			name: "no fields",
+		},
+		{
+			name:     "allowed keys",
+			fields:   []string{"tenant_id", "user_id"},
+			expected: map[string]string{"tenant_id": "acme", "user_id": "42"},
+		},
+		{
+			name:     "globs",
+			fields:   []string{"flag.*"},
+			expected: map[string]string{"flag.checkout": "v2", "flag.search": "off"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &syncBuffer{}
+			exporter := tracetest.NewInMemoryExporter()
+
+			_, o, err := go11y.New(context.Background(), go11y.WithOutput(buf), go11y.WithTraceExporter(keepSpans{exporter}), go11y.WithBaggageFields(tc.fields...))
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+			defer o.Close()
+
+			fields := sendWithBaggage(t, o, buf, map[string]string{
+				"tenant_id":     "acme",
+				"user_id":       "42",
+				"flag.checkout": "v2",
+				"flag.search":   "off",
+				"session":       "s3cr3t",
+			})
+
+			o.Close()
+			spans := exporter.GetSpans()
+
+			for _, k := range []string{"tenant_id", "user_id", "flag.checkout", "flag.search", "session"} {
+				v, ok := tc.expected[k]
+
+				if actual, logged := fields[k]; logged != ok || (ok && actual != v) {
+					t.Errorf("expected %s to be logged as %q (%t), got %v", k, v, ok, actual)
+				}
+
+				attr := ""
+				for _, span := range spans {
+					for _, kv := range span.Attributes {
+						if string(kv.Key) == k {
+							attr = kv.Value.Emit()
+						}
+					}
+				}
+
+				if attr != v {
+					t.Errorf("expected the span attribute %s to be %q, got %q", k, v, attr)
+				}
+			}
+		})
+	}
+}
+
+func TestBaggageFieldsConfig(t *testing.T) {
+	path := writeConfigFile(t, "go11y.yaml", "tracing:\n  baggage_fields: [tenant_id]\n")
+
+	buf := &syncBuffer{}
+	o := newReloadObserver(t, path, buf)
+	defer o.Close()
+
+	if value := o.EffectiveConfig()["tracing.baggage_fields"]; value.Value != "tenant_id" || value.Source != go11y.SourceFile {
+		t.Errorf("expected the baggage fields from the file, got %+v", value)
+	}
+
+	members := map[string]string{"tenant_id": "acme", "user_id": "42"}
+
+	if fields := sendWithBaggage(t, o, buf, members); fields["tenant_id"] != "acme" || fields["user_id"] != nil {
+		t.Errorf("expected only the tenant to be logged, got %v", fields)
+	}
+
+	if err := os.WriteFile(path, []byte("tracing:\n  baggage_fields: [user_id]\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	if err := o.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	if fields := sendWithBaggage(t, o, buf, members); fields["user_id"] != "42" || fields["tenant_id"] != nil {
+		t.Errorf("expected only the user to be logged after reloading, got %v", fields)
+	}
+
+	t.Setenv(go11y.BaggageFieldsEnv, "tenant_id, flag.*")
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err = go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	if value := o.EffectiveConfig()["tracing.baggage_fields"]; value.Value != "tenant_id, flag.*" || value.Source != go11y.SourceEnv {
+		t.Errorf("expected %s to take precedence over the file, got %+v", go11y.BaggageFieldsEnv, value)
+	}
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  baggage_fields: [\"[tenant\"]\n"))
+	if err == nil || !strings.Contains(err.Error(), "tracing.baggage_fields: invalid pattern '[tenant'") {
+		t.Errorf("expected an error about the invalid pattern, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package logrusbridge routes logrus entries into a go11y Observer, mapping the logrus levels onto the go11y levels and
+// keeping the entry's fields as attributes.
+package logrusbridge
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook that logs every entry via an Observer
+type Hook struct {
+	handler slog.Handler
+}
+
+// NewHook creates a Hook that logs entries via the observer
+func NewHook(o *go11y.Observer) *Hook {
+	return &Hook{handler: o.Handler()}
+}
+
+// Attach adds a Hook for the observer to the logger, and stops the logger writing its own output, so the entries are
+// only written by the observer. The logger's level is lowered to TraceLevel so that the observer's level decides what
+// is logged.
+func Attach(logger *logrus.Logger, o *go11y.Observer) {
+	logger.AddHook(NewHook(o))
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.TraceLevel)
+}
+
+// Level maps a logrus level onto a go11y level
+func Level(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return go11y.LevelFatal
+	case logrus.ErrorLevel:
+		return go11y.LevelError
+	case logrus.WarnLevel:
+		return go11y.LevelWarning
+	case logrus.InfoLevel:
+		return go11y.LevelInfo
+	case logrus.DebugLevel:
+		return go11y.LevelDebug
+	default:
+		return go11y.LevelDevelop
+	}
+}
+
+// Levels returns all the logrus levels, as the observer decides which are logged
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire logs the entry via the observer
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	level := Level(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+
+	r := slog.NewRecord(entry.Time, level, entry.Message, pc)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		r.AddAttrs(slog.Any(k, v))
+	}
+
+	return h.handler.Handle(ctx, r)
+}
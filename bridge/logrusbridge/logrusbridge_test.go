@@ -0,0 +1,62 @@
+package logrusbridge_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/bridge/logrusbridge"
+	"github.com/jsnfwlr/go11y/go11ytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAttach(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := logrus.New()
+	logrusbridge.Attach(logger, o.Observer)
+
+	logger.WithField("user", "alice").WithField("count", 3).Info("logged in")
+	logger.WithError(errors.New("boom")).Error("failed")
+	logger.Trace("tracing")
+
+	o.RequireLogged(go11y.LevelInfo, "logged in", "user", "alice", "count", 3)
+	o.RequireLogged(go11y.LevelError, "failed", "error", "boom")
+	o.RequireLogged(go11y.LevelDevelop, "tracing")
+}
+
+func TestLevel(t *testing.T) {
+	testCases := []struct {
+		level    logrus.Level
+		expected string
+	}{
+		{level: logrus.TraceLevel, expected: "DEVELOP"},
+		{level: logrus.DebugLevel, expected: "DEBUG"},
+		{level: logrus.InfoLevel, expected: "INFO"},
+		{level: logrus.WarnLevel, expected: "WARN"},
+		{level: logrus.ErrorLevel, expected: "ERR"},
+		{level: logrus.FatalLevel, expected: "FATAL"},
+		{level: logrus.PanicLevel, expected: "FATAL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.level.String(), func(t *testing.T) {
+			if got := go11y.LevelName(logrusbridge.Level(tc.level)); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := logrus.New()
+	logrusbridge.Attach(logger, o.Observer)
+
+	ctx, span := o.Tracer("test").Start(o.Context(), "bridged")
+	logger.WithContext(ctx).Info("in a span")
+	span.End()
+
+	o.RequireLogged(go11y.LevelInfo, "in a span", go11y.FieldTraceID, span.SpanContext().TraceID().String(), go11y.FieldSpanID, span.SpanContext().SpanID().String())
+}
@@ -0,0 +1,86 @@
+// Package stdlogbridge routes the output of the standard library's log package into a go11y Observer.
+package stdlogbridge
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+// levelPrefix matches a level at the start of a line, such as "[WARN] ", "ERROR: " or "debug "
+var levelPrefix = regexp.MustCompile(`^\[?([A-Za-z]+)\]?:?\s+`)
+
+// Writer is an io.Writer that logs each line written to it via an Observer. Lines that start with the name of a level,
+// such as "[WARN] " or "error: ", are logged at that level with the prefix removed; all other lines are logged at the
+// default level.
+type Writer struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+// NewWriter creates a Writer that logs lines without a level prefix at the level
+func NewWriter(o *go11y.Observer, level slog.Level) *Writer {
+	return &Writer{
+		handler: o.Handler(),
+		level:   level,
+	}
+}
+
+// Write logs the line, using the caller of the log package's print functions as the source
+func (w *Writer) Write(p []byte) (n int, fault error) {
+	msg := string(bytes.TrimRight(p, "\r\n"))
+	level := w.level
+
+	if m := levelPrefix.FindStringSubmatch(msg); m != nil {
+		if l, err := go11y.ParseLevelStrict(m[1]); err == nil {
+			level = l
+			msg = msg[len(m[0]):]
+		}
+	}
+
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	var pcs [1]uintptr
+	// skip [runtime.Callers, this function, log.(*Logger).output, log.Printf (or similar)]
+	runtime.Callers(4, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if err := w.handler.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// New returns a *log.Logger that logs each line via the observer, for dependencies that accept a logger
+func New(o *go11y.Observer, level slog.Level) *log.Logger {
+	return log.New(NewWriter(o, level), "", 0)
+}
+
+// Redirect sends the output of the log package's default logger to the observer, and returns a function that restores
+// the previous output, prefix and flags
+func Redirect(o *go11y.Observer, level slog.Level) (restore func()) {
+	output, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+
+	log.SetOutput(NewWriter(o, level))
+	log.SetPrefix("")
+	log.SetFlags(0)
+
+	return func() {
+		log.SetOutput(output)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}
+}
+
+var _ io.Writer = (*Writer)(nil)
@@ -0,0 +1,52 @@
+package stdlogbridge_test
+
+import (
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/bridge/stdlogbridge"
+	"github.com/jsnfwlr/go11y/go11ytest"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expLevel slog.Level
+		expMsg   string
+	}{
+		{name: "no prefix", line: "plain message", expLevel: go11y.LevelInfo, expMsg: "plain message"},
+		{name: "bracketed prefix", line: "[WARN] disk almost full", expLevel: go11y.LevelWarning, expMsg: "disk almost full"},
+		{name: "colon prefix", line: "error: could not connect", expLevel: go11y.LevelError, expMsg: "could not connect"},
+		{name: "unknown prefix", line: "hello world", expLevel: go11y.LevelInfo, expMsg: "hello world"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := go11ytest.NewObserver(t)
+
+			logger := stdlogbridge.New(o.Observer, go11y.LevelInfo)
+			logger.Println(tc.line)
+
+			o.RequireLogged(tc.expLevel, tc.expMsg)
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	flags := log.Flags()
+	restore := stdlogbridge.Redirect(o.Observer, go11y.LevelNotice)
+
+	log.Printf("redirected %d", 1)
+	restore()
+
+	o.RequireLogged(go11y.LevelNotice, "redirected 1")
+
+	if log.Flags() != flags {
+		t.Errorf("expected flags to be restored to %d, got %d", flags, log.Flags())
+	}
+}
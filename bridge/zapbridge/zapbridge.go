@@ -0,0 +1,136 @@
+// Package zapbridge provides a zapcore.Core that routes zap logs into a go11y Observer, mapping the zap levels onto the
+// go11y levels and keeping the fields as attributes.
+package zapbridge
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/jsnfwlr/go11y"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a zapcore.Core that logs entries via an Observer
+type Core struct {
+	handler slog.Handler
+	ctx     context.Context // from a Context field passed to With, if there was one
+}
+
+// NewCore creates a Core that logs entries via the observer
+func NewCore(o *go11y.Observer) *Core {
+	return &Core{handler: o.Handler()}
+}
+
+// NewLogger creates a zap.Logger that logs via the observer, recording the caller of the zap logging functions as the source
+func NewLogger(o *go11y.Observer, options ...zap.Option) *zap.Logger {
+	return zap.New(NewCore(o), append([]zap.Option{zap.AddCaller()}, options...)...)
+}
+
+// Level maps a zap level onto a go11y level
+func Level(level zapcore.Level) slog.Level {
+	switch {
+	case level >= zapcore.PanicLevel:
+		return go11y.LevelFatal
+	case level >= zapcore.ErrorLevel:
+		return go11y.LevelError
+	case level == zapcore.WarnLevel:
+		return go11y.LevelWarning
+	case level == zapcore.InfoLevel:
+		return go11y.LevelInfo
+	default:
+		return go11y.LevelDebug
+	}
+}
+
+// Context returns a field that passes the context to the observer, so the entry has the trace and span IDs of the span
+// in it. It can be passed with the other fields of an entry, or to With for every entry.
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: "context", Type: zapcore.SkipType, Interface: ctx}
+}
+
+// context returns the context from the last Context field, or the core's context if there isn't one
+func (c *Core) context(fields []zapcore.Field) context.Context {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if ctx, ok := fields[i].Interface.(context.Context); ok && fields[i].Type == zapcore.SkipType {
+			return ctx
+		}
+	}
+
+	if c.ctx != nil {
+		return c.ctx
+	}
+
+	return context.Background()
+}
+
+// Enabled reports whether the observer logs entries at the level
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(c.context(nil), Level(level))
+}
+
+// With returns a Core that adds the fields to every entry
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	return &Core{handler: c.handler.WithAttrs(fieldsToAttrs(fields)), ctx: c.context(fields)}
+}
+
+// Check adds the Core to the checked entry if the observer logs entries at its level
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+// Write logs the entry and fields via the observer
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if entry.Caller.Defined {
+		pc = entry.Caller.PC
+	}
+
+	r := slog.NewRecord(entry.Time, Level(entry.Level), entry.Message, pc)
+
+	if entry.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+
+	r.AddAttrs(fieldsToAttrs(fields)...)
+
+	if entry.Stack != "" {
+		r.AddAttrs(slog.String("stacktrace", entry.Stack))
+	}
+
+	return c.handler.Handle(c.context(fields), r)
+}
+
+// Sync does nothing, as the observer writes each entry as it is logged
+func (c *Core) Sync() error {
+	return nil
+}
+
+func fieldsToAttrs(fields []zapcore.Field) []slog.Attr {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, enc.Fields[k]))
+	}
+
+	return attrs
+}
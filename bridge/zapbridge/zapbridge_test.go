@@ -0,0 +1,41 @@
+package zapbridge_test
+
+import (
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/bridge/zapbridge"
+	"github.com/jsnfwlr/go11y/go11ytest"
+	"go.uber.org/zap"
+)
+
+func TestNewLogger(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := zapbridge.NewLogger(o.Observer).Named("worker").With(zap.String("job", "sync"))
+
+	logger.Info("started", zap.Int("attempt", 2))
+	logger.Warn("slow", zap.Duration("elapsed", 0))
+	logger.DPanic("odd")
+	logger.Debug("details", zap.Bool("verbose", true))
+
+	o.RequireLogged(go11y.LevelInfo, "started", "job", "sync", "attempt", 2, "logger", "worker")
+	o.RequireLogged(go11y.LevelWarning, "slow", "job", "sync")
+	o.RequireLogged(go11y.LevelError, "odd")
+	o.RequireLogged(go11y.LevelDebug, "details", "verbose", true)
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := zapbridge.NewLogger(o.Observer)
+
+	ctx, span := o.Tracer("test").Start(o.Context(), "bridged")
+	logger.Info("in a span", zapbridge.Context(ctx))
+	logger.With(zapbridge.Context(ctx)).Info("with the span")
+	span.End()
+
+	for _, msg := range []string{"in a span", "with the span"} {
+		o.RequireLogged(go11y.LevelInfo, msg, go11y.FieldTraceID, span.SpanContext().TraceID().String(), go11y.FieldSpanID, span.SpanContext().SpanID().String())
+	}
+}
@@ -0,0 +1,152 @@
+// Package zerologbridge routes zerolog events into a go11y Observer, mapping the zerolog levels onto the go11y levels and
+// keeping the event's fields as attributes in the order they were added.
+package zerologbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/rs/zerolog"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// Writer is a zerolog.LevelWriter that decodes the JSON events written by zerolog and logs them via an Observer
+type Writer struct {
+	handler slog.Handler
+}
+
+// NewWriter creates a Writer that logs events via the observer
+func NewWriter(o *go11y.Observer) *Writer {
+	return &Writer{handler: o.Handler()}
+}
+
+// NewLogger creates a zerolog.Logger that logs via the observer. The events logged with a context (from Ctx) have the
+// trace and span IDs of the span in it.
+func NewLogger(o *go11y.Observer) zerolog.Logger {
+	return zerolog.New(NewWriter(o)).Level(zerolog.TraceLevel).Hook(zerolog.HookFunc(traceHook))
+}
+
+// traceHook adds the trace and span IDs of the span in the event's context, as the Writer only gets the encoded event
+func traceHook(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if sc := otelTrace.SpanContextFromContext(e.GetCtx()); sc.IsValid() {
+		e.Str(go11y.FieldTraceID, sc.TraceID().String()).Str(go11y.FieldSpanID, sc.SpanID().String())
+	}
+}
+
+// Level maps a zerolog level onto a go11y level
+func Level(level zerolog.Level) slog.Level {
+	switch level {
+	case zerolog.PanicLevel, zerolog.FatalLevel:
+		return go11y.LevelFatal
+	case zerolog.ErrorLevel:
+		return go11y.LevelError
+	case zerolog.WarnLevel:
+		return go11y.LevelWarning
+	case zerolog.DebugLevel:
+		return go11y.LevelDebug
+	case zerolog.TraceLevel:
+		return go11y.LevelDevelop
+	default:
+		return go11y.LevelInfo
+	}
+}
+
+// Write logs an event, taking the level from its level field
+func (w *Writer) Write(p []byte) (n int, fault error) {
+	level := zerolog.NoLevel
+
+	var fields struct {
+		Level string `json:"level"`
+	}
+	if err := json.Unmarshal(p, &fields); err == nil {
+		if l, err := zerolog.ParseLevel(fields.Level); err == nil {
+			level = l
+		}
+	}
+
+	return w.WriteLevel(level, p)
+}
+
+// WriteLevel logs an event at the level
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, fault error) {
+	ctx := context.Background()
+
+	lvl := Level(level)
+	if !w.handler.Enabled(ctx, lvl) {
+		return len(p), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0, fmt.Errorf("could not decode zerolog event: %s", p)
+	}
+
+	ts := time.Now()
+	msg := ""
+	attrs := []slog.Attr{}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return 0, fmt.Errorf("could not decode zerolog event key: %w", err)
+		}
+
+		key, _ := t.(string)
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return 0, fmt.Errorf("could not decode zerolog event value for '%s': %w", key, err)
+		}
+
+		switch key {
+		case zerolog.LevelFieldName:
+			continue
+		case zerolog.MessageFieldName:
+			msg = fmt.Sprintf("%v", value)
+			continue
+		case zerolog.TimestampFieldName:
+			if s, ok := value.(string); ok {
+				if parsed, err := time.Parse(zerolog.TimeFieldFormat, s); err == nil {
+					ts = parsed
+					continue
+				}
+			}
+		}
+
+		attrs = append(attrs, slog.Any(key, number(value)))
+	}
+
+	r := slog.NewRecord(ts, lvl, msg, 0)
+	r.AddAttrs(attrs...)
+
+	if err := w.handler.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// number converts JSON numbers to int64 where possible, and float64 otherwise
+func number(value any) any {
+	n, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+
+	return n.String()
+}
@@ -0,0 +1,37 @@
+package zerologbridge_test
+
+import (
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/bridge/zerologbridge"
+	"github.com/jsnfwlr/go11y/go11ytest"
+)
+
+func TestNewLogger(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := zerologbridge.NewLogger(o.Observer).With().Str("component", "cache").Logger()
+
+	logger.Info().Int("hits", 10).Float64("ratio", 0.5).Msg("stats")
+	logger.Warn().Str("key", "a").Msg("evicted")
+	logger.Trace().Msg("lookup")
+	logger.Log().Msg("no level")
+
+	o.RequireLogged(go11y.LevelInfo, "stats", "component", "cache", "hits", 10, "ratio", 0.5)
+	o.RequireLogged(go11y.LevelWarning, "evicted", "key", "a")
+	o.RequireLogged(go11y.LevelDevelop, "lookup")
+	o.RequireLogged(go11y.LevelInfo, "no level")
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := zerologbridge.NewLogger(o.Observer)
+
+	ctx, span := o.Tracer("test").Start(o.Context(), "bridged")
+	logger.Info().Ctx(ctx).Msg("in a span")
+	span.End()
+
+	o.RequireLogged(go11y.LevelInfo, "in a span", go11y.FieldTraceID, span.SpanContext().TraceID().String(), go11y.FieldSpanID, span.SpanContext().SpanID().String())
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+// configCommand prints or validates the configuration loaded from the file, or from GO11Y_CONFIG or the environment
+// variables if there isn't one
+func configCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("go11y config "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	file := fs.String("file", "", "the configuration file to load, instead of GO11Y_CONFIG or the environment variables")
+	asJSON := fs.Bool("json", false, "print the configuration as JSON")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	var (
+		cfg *go11y.Configuration
+		err error
+	)
+
+	if *file != "" {
+		cfg, err = go11y.LoadConfigFile(*file)
+	} else {
+		cfg, err = go11y.LoadConfig()
+	}
+
+	switch args[0] {
+	case "validate":
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+			return 1
+		}
+
+		fmt.Fprintln(stdout, "the configuration is valid")
+
+		return 0
+	case "print":
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+			return 1
+		}
+
+		effective, err := go11y.ResolveConfig(go11y.WithConfig(cfg))
+		if err != nil {
+			fmt.Fprintf(stderr, "could not resolve the configuration: %v\n", err)
+			return 1
+		}
+
+		if *asJSON {
+			enc := json.NewEncoder(stdout)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(effective)
+
+			return 0
+		}
+
+		fmt.Fprint(stdout, effective.String())
+
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown config command '%s'\n", args[0])
+		usage(stderr)
+
+		return 2
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(valid, []byte("service:\n  name: checkout\ndb:\n  constr: postgres://go11y:hunter2@db/go11y\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("log:\n  level: wraning\n  format: xml\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		args      []string
+		expCode   int
+		expOutput []string
+		expErrors []string
+	}{
+		{name: "print", args: []string{"config", "print", "-file", valid}, expOutput: []string{"service.name", "= checkout (file " + valid + ")", "postgres://go11y:xxxxx@db/go11y"}},
+		{name: "print json", args: []string{"config", "print", "-file", valid, "-json"}, expOutput: []string{`"service.name": {`, `"source": "file"`}},
+		{name: "validate", args: []string{"config", "validate", "-file", valid}, expOutput: []string{"valid"}},
+		{name: "validate invalid", args: []string{"config", "validate", "-file", invalid}, expCode: 1, expErrors: []string{"log.level", "log.format"}},
+		{name: "unknown", args: []string{"config", "check"}, expCode: 2, expErrors: []string{"unknown config command"}},
+		{name: "no command", args: nil, expCode: 2, expErrors: []string{"Usage"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+			if code := run(tc.args, stdout, stderr); code != tc.expCode {
+				t.Errorf("expected exit code %d, got %d: %s", tc.expCode, code, stderr.String())
+			}
+
+			for _, exp := range tc.expOutput {
+				if !strings.Contains(stdout.String(), exp) {
+					t.Errorf("expected the output to contain %q, got %s", exp, stdout.String())
+				}
+			}
+
+			for _, exp := range tc.expErrors {
+				if !strings.Contains(stderr.String(), exp) {
+					t.Errorf("expected the errors to contain %q, got %s", exp, stderr.String())
+				}
+			}
+
+			if strings.Contains(stdout.String(), "hunter2") {
+				t.Errorf("expected the password to be masked, got %s", stdout.String())
+			}
+		})
+	}
+}
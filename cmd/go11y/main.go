@@ -0,0 +1,52 @@
+// Command go11y inspects the configuration go11y resolves from the environment variables and configuration files, and
+// the spans written by the stdout and file exporters.
+//
+// Usage:
+//
+//	go11y config print [-file path] [-json]
+//	go11y config validate [-file path]
+//	go11y trace view [file...]
+//	go11y trace replay [-url url] [-headers key=value,...] file...
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the args, and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "config":
+		return configCommand(args[1:], stdout, stderr)
+	case "trace":
+		return traceCommand(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command '%s'\n", args[0])
+		usage(stderr)
+		return 2
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage:
+  go11y config print [-file path] [-json]   show the effective configuration and where each value came from
+  go11y config validate [-file path]        check the configuration, reporting all of its problems
+  go11y trace view [file...]                show the spans in the files (or stdin) as a tree of each trace
+  go11y trace replay [-url url] [-headers key=value,...] file...
+                                            send the spans in the files to a collector
+`)
+}
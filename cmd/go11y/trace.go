@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// traceCommand views the spans written by the stdout and file exporters, or replays them into a collector
+func traceCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("go11y trace "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	url := fs.String("url", "http://localhost:4318/v1/traces", "the OTLP/HTTP endpoint of the collector to replay the spans into")
+	headers := fs.String("headers", "", "comma separated key=value pairs sent with each request to the collector")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch args[0] {
+	case "view":
+		spans, err := readSpans(fs.Args())
+		if err != nil {
+			fmt.Fprintf(stderr, "could not read the spans: %v\n", err)
+			return 1
+		}
+
+		renderTraces(stdout, spans)
+
+		return 0
+	case "replay":
+		if fs.NArg() == 0 {
+			fmt.Fprintln(stderr, "the files to replay are required")
+			return 2
+		}
+
+		sent, err := replay(*url, *headers, fs.Args())
+		if err != nil {
+			fmt.Fprintf(stderr, "could not replay the spans: %v\n", err)
+			return 1
+		}
+
+		fmt.Fprintf(stdout, "replayed %d requests to %s\n", sent, *url)
+
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown trace command '%s'\n", args[0])
+		usage(stderr)
+
+		return 2
+	}
+}
+
+// exportRequest is the part of an OTLP/JSON export request that is rendered
+type exportRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []keyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []span `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type span struct {
+	TraceID      string     `json:"traceId"`
+	SpanID       string     `json:"spanId"`
+	ParentSpanID string     `json:"parentSpanId"`
+	Name         string     `json:"name"`
+	Start        nanos      `json:"startTimeUnixNano"`
+	End          nanos      `json:"endTimeUnixNano"`
+	Attributes   []keyValue `json:"attributes"`
+	Events       []struct {
+		Name       string     `json:"name"`
+		Time       nanos      `json:"timeUnixNano"`
+		Attributes []keyValue `json:"attributes"`
+	} `json:"events"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+
+	service  string  // the service.name of the resource the span belongs to
+	children []*span // the spans in the file with this span as their parent
+}
+
+type keyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// nanos is a time in nanoseconds since the epoch, which OTLP/JSON encodes as a string
+type nanos uint64
+
+func (n *nanos) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid time '%s': %w", data, err)
+	}
+	*n = nanos(v)
+
+	return nil
+}
+
+// readSpans reads the spans from the lines of OTLP/JSON in the files, or from stdin if there aren't any
+func readSpans(paths []string) (spans []*span, fault error) {
+	read := func(name string, r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+
+			req := exportRequest{}
+			if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+				return fmt.Errorf("%s:%d: %w", name, line, err)
+			}
+
+			for _, rs := range req.ResourceSpans {
+				service := ""
+				for _, kv := range rs.Resource.Attributes {
+					if kv.Key == "service.name" {
+						service = formatValue(kv.Value)
+					}
+				}
+
+				for _, ss := range rs.ScopeSpans {
+					for i := range ss.Spans {
+						s := ss.Spans[i]
+						s.service = service
+						spans = append(spans, &s)
+					}
+				}
+			}
+		}
+
+		return scanner.Err()
+	}
+
+	if len(paths) == 0 {
+		return spans, read("stdin", os.Stdin)
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = read(path, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return spans, nil
+}
+
+// renderTraces writes each trace as a tree of its spans, indented under their parents, with their durations and
+// attributes. The traces and the spans within them are in the order they started.
+func renderTraces(w io.Writer, spans []*span) {
+	byID := map[string]*span{}
+	for _, s := range spans {
+		byID[s.TraceID+s.SpanID] = s
+	}
+
+	roots := []*span{}
+	for _, s := range spans {
+		if parent, ok := byID[s.TraceID+s.ParentSpanID]; ok && s.ParentSpanID != "" {
+			parent.children = append(parent.children, s)
+			continue
+		}
+		roots = append(roots, s)
+	}
+
+	sortSpans(roots)
+
+	traces := []string{}
+	byTrace := map[string][]*span{}
+	for _, s := range roots {
+		if _, ok := byTrace[s.TraceID]; !ok {
+			traces = append(traces, s.TraceID)
+		}
+		byTrace[s.TraceID] = append(byTrace[s.TraceID], s)
+	}
+
+	for i, traceID := range traces {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		header := "trace " + traceID
+		if service := byTrace[traceID][0].service; service != "" {
+			header += " (" + service + ")"
+		}
+		fmt.Fprintln(w, header)
+
+		for _, s := range byTrace[traceID] {
+			renderSpan(w, s, 1)
+		}
+	}
+}
+
+func renderSpan(w io.Writer, s *span, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	line := fmt.Sprintf("%s%s %s", indent, s.Name, duration(s.Start, s.End))
+	if attrs := formatAttributes(s.Attributes); attrs != "" {
+		line += " " + attrs
+	}
+
+	if s.Status.Code == 2 {
+		line += " [error"
+		if s.Status.Message != "" {
+			line += ": " + s.Status.Message
+		}
+		line += "]"
+	}
+
+	fmt.Fprintln(w, line)
+
+	for _, e := range s.Events {
+		event := fmt.Sprintf("%s  * %s +%s", indent, e.Name, duration(s.Start, e.Time))
+		if attrs := formatAttributes(e.Attributes); attrs != "" {
+			event += " " + attrs
+		}
+		fmt.Fprintln(w, event)
+	}
+
+	sortSpans(s.children)
+	for _, child := range s.children {
+		renderSpan(w, child, depth+1)
+	}
+}
+
+func sortSpans(spans []*span) {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+}
+
+func duration(start, end nanos) string {
+	if end < start {
+		return "0s"
+	}
+
+	return time.Duration(end - start).Round(time.Microsecond).String()
+}
+
+// formatAttributes formats the attributes as key=value pairs, sorted by their keys
+func formatAttributes(attrs []keyValue) string {
+	pairs := make([]string, 0, len(attrs))
+	for _, kv := range attrs {
+		pairs = append(pairs, kv.Key+"="+formatValue(kv.Value))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
+
+// formatValue formats an OTLP/JSON AnyValue, which has a single field named for the type of the value
+func formatValue(v map[string]any) string {
+	for kind, value := range v {
+		switch kind {
+		case "arrayValue":
+			array, _ := value.(map[string]any)
+			values, _ := array["values"].([]any)
+
+			parts := make([]string, 0, len(values))
+			for _, item := range values {
+				m, _ := item.(map[string]any)
+				parts = append(parts, formatValue(m))
+			}
+
+			return "[" + strings.Join(parts, ",") + "]"
+		case "stringValue":
+			s := fmt.Sprint(value)
+			if strings.ContainsAny(s, " \t\"") {
+				return strconv.Quote(s)
+			}
+
+			return s
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+
+	return ""
+}
+
+// replay sends each line of the files to the collector as an OTLP/JSON export request, and returns how many were sent
+func replay(url, headers string, paths []string) (sent int, fault error) {
+	header := http.Header{}
+	for _, pair := range strings.Split(headers, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return 0, fmt.Errorf("the headers must be key=value pairs, got '%s'", pair)
+		}
+		header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return sent, err
+		}
+
+		for i, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(line))
+			if err != nil {
+				return sent, err
+			}
+			req.Header = header.Clone()
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return sent, fmt.Errorf("%s:%d: %w", path, i+1, err)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return sent, fmt.Errorf("%s:%d: the collector responded with %s", path, i+1, resp.Status)
+			}
+
+			sent++
+		}
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// writeSpans writes a trace with nested spans to a file with the file exporter, and returns the path of the file
+func writeSpans(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+	exporter, err := go11y.NewFileExporter(context.Background(), go11y.FileExporterConfig{Path: path})
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+
+	tp := otelSDKTrace.NewTracerProvider(
+		otelSDKTrace.WithSyncer(exporter),
+		otelSDKTrace.WithResource(otelResource.NewSchemaless(attribute.String("service.name", "checkout"))),
+	)
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /orders")
+	root.SetAttributes(attribute.String("http.method", "GET"), attribute.Int("http.status_code", 500))
+
+	_, query := tracer.Start(ctx, "query")
+	query.SetAttributes(attribute.String("db.system", "postgresql"))
+	query.AddEvent("retry", otelTrace.WithAttributes(attribute.Int("attempt", 2)))
+	query.SetStatus(codes.Error, "timeout")
+	query.End()
+
+	_, render := tracer.Start(ctx, "render")
+	render.End()
+
+	root.End()
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	return path
+}
+
+func TestTraceView(t *testing.T) {
+	path := writeSpans(t)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := run([]string{"trace", "view", path}, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got:\n%s", stdout.String())
+	}
+
+	expected := []struct{ prefix, contains string }{
+		{"trace ", "(checkout)"},
+		{"  GET /orders ", "http.method=GET http.status_code=500"},
+		{"    query ", "db.system=postgresql [error: timeout]"},
+		{"      * retry +", "attempt=2"},
+		{"    render ", ""},
+	}
+
+	for i, exp := range expected {
+		if !strings.HasPrefix(lines[i], exp.prefix) || !strings.Contains(lines[i], exp.contains) {
+			t.Errorf("expected line %d to start with %q and contain %q, got %q", i+1, exp.prefix, exp.contains, lines[i])
+		}
+	}
+
+	if code := run([]string{"trace", "view", filepath.Join(t.TempDir(), "missing.jsonl")}, stdout, stderr); code != 1 {
+		t.Errorf("expected exit code 1 for a missing file, got %d", code)
+	}
+}
+
+func TestTraceReplay(t *testing.T) {
+	path := writeSpans(t)
+
+	var (
+		mu       sync.Mutex
+		requests int
+		headers  http.Header
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		headers = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := run([]string{"trace", "replay", "-url", srv.URL + "/v1/traces", "-headers", "x-api-key=abc123", path}, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the syncer exports each span on its own, so there is a line for each of them
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	if headers.Get("Content-Type") != "application/json" || headers.Get("X-Api-Key") != "abc123" {
+		t.Errorf("expected the JSON content type and the header, got %v", headers)
+	}
+
+	if !strings.Contains(stdout.String(), "replayed 3 requests") {
+		t.Errorf("expected the number of requests to be reported, got %s", stdout.String())
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
+	"go.opentelemetry.io/otel/propagation"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Configuration is a struct that holds the reference configuration for go11y.
@@ -18,6 +21,40 @@ type Configuration struct {
 	serviceName string
 	trimModules []string
 	trimPaths   []string
+
+	environment      string
+	serviceVersion   string
+	serviceNamespace string
+	instanceID       string
+
+	// settings that the Configurator interface doesn't cover, which are only set by LoadConfig and LoadConfigFile
+	format              string
+	sinks               []SinkConfig
+	packageLevels       map[string]slog.Level
+	traceHeaders        map[string]string
+	traceExporter       string
+	traceFile           FileExporterConfig
+	traceProtocol       string
+	traceTimeout        time.Duration
+	compression         string
+	traceTLS            TLSConfig
+	resourceAttrs       map[string]string
+	sampler             otelSDKTrace.Sampler
+	tailSampling        *TailSamplingConfig
+	propagator          propagation.TextMapPropagator
+	baggageFields       []string
+	errorStatusSeverity string
+	tracingDisabled     bool
+	redactionRules      []RedactionRule
+	skipMigrations      bool
+
+	// where the configuration was loaded from, so it can be reloaded
+	path    string
+	fromEnv bool
+
+	sources map[string]ConfigValue // where each value came from, keyed by its path
+
+	warnings []string // the invalid values that were ignored, which the observer logs once it is set up
 }
 
 // Configurator is an interface that defines the methods required for configuration of go11y.
@@ -37,21 +74,46 @@ type Configurator interface {
 type interimConfig struct {
 	StrLevel    string `env:"LOG_LEVEL" envDefault:"debug"`
 	OtelURL     string `env:"OTEL_URL" envDefault:""`
-	DBConStr    string `env:"DB_CONSTR" envDefault:""`
 	ServiceName string `env:"OTEL_SERVICE_NAME" envDefault:""`
 	TrimModules string `env:"TRIM_MODULES" envDefault:""`
 	TrimPaths   string `env:"TRIM_PATHS" envDefault:""`
+
+	Environment      string `env:"DEPLOYMENT_ENVIRONMENT" envDefault:""`
+	ServiceVersion   string `env:"SERVICE_VERSION" envDefault:""`
+	ServiceNamespace string `env:"SERVICE_NAMESPACE" envDefault:""`
+	InstanceID       string `env:"SERVICE_INSTANCE_ID" envDefault:""`
 }
 
-// LoadConfig loads the configuration from environment variables.
+// LoadConfig loads the configuration from environment variables, including the standard OpenTelemetry ones such as
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_TRACES_SAMPLER. OTEL_URL takes precedence over the OTLP endpoint variables.
 // It returns a Configuration instance that implements the Configurator interface.
 // If any required environment variable is missing or invalid, it returns an error.
+// If the GO11Y_CONFIG environment variable is set, the configuration is loaded from that file with LoadConfigFile instead.
 func LoadConfig() (cfg *Configuration, fault error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		return LoadConfigFile(path)
+	}
+
 	h := interimConfig{}
 	if err := env.Parse(&h); err != nil {
 		return nil, fmt.Errorf("could not load config: %w", err)
 	}
 
+	logLevel, err := ParseLevelStrict(h.StrLevel)
+	if err != nil {
+		return nil, fmt.Errorf("could not load config: invalid LOG_LEVEL: %w", err)
+	}
+
+	oe, err := loadOTelEnv()
+	if err != nil {
+		return nil, fmt.Errorf("could not load config: %w", err)
+	}
+
+	dbConStr, err := loadDBEnv()
+	if err != nil {
+		return nil, fmt.Errorf("could not load config: %w", err)
+	}
+
 	trimModules := strings.Split(h.TrimModules, ",")
 
 	path, _ := os.Getwd()
@@ -64,17 +126,117 @@ func LoadConfig() (cfg *Configuration, fault error) {
 
 	c := &Configuration{
 		otelURL:     h.OtelURL,
-		dbConStr:    h.DBConStr,
-		strLevel:    ParseLevel(h.StrLevel).String(),
-		logLevel:    ParseLevel(h.StrLevel),
+		dbConStr:    dbConStr,
+		strLevel:    LevelName(logLevel),
+		logLevel:    logLevel,
 		serviceName: h.ServiceName,
 		trimModules: trimModules,
 		trimPaths:   trimPaths,
+		fromEnv:     true,
+
+		environment:      h.Environment,
+		serviceVersion:   h.ServiceVersion,
+		serviceNamespace: h.ServiceNamespace,
+		instanceID:       h.InstanceID,
+	}
+
+	c.applyOTelEnv(oe)
+
+	if c.traceExporter == ExporterFile && c.traceFile.Path == "" {
+		return nil, fmt.Errorf("could not load config: %s: is required when OTEL_TRACES_EXPORTER is %s", TracesFileEnv, ExporterFile)
+	}
+
+	if c.serviceVersion == "" {
+		c.serviceVersion = buildVersion()
 	}
 
+	c.sources = envSources("", nil)
+
 	return c, nil
 }
 
+// applyOTelEnv sets the values from the standard OpenTelemetry environment variables. The endpoint and the identity of
+// the service (from the resource attributes) are only used if they are not already set, and the headers are added to
+// any that are already set. The other exporter settings are only replaced by the variables that are set.
+func (c *Configuration) applyOTelEnv(oe otelEnv) {
+	for _, f := range []struct {
+		value *string
+		keys  []string
+	}{
+		{&c.serviceName, []string{"service.name"}},
+		{&c.environment, []string{"deployment.environment.name", "deployment.environment"}},
+		{&c.serviceVersion, []string{"service.version"}},
+		{&c.serviceNamespace, []string{"service.namespace"}},
+		{&c.instanceID, []string{"service.instance.id"}},
+	} {
+		for _, k := range f.keys {
+			if *f.value == "" {
+				*f.value = oe.resourceAttrs[k]
+			}
+		}
+	}
+
+	if len(oe.headers) != 0 && c.traceHeaders == nil {
+		c.traceHeaders = map[string]string{}
+	}
+	for k, v := range oe.headers {
+		c.traceHeaders[k] = v
+	}
+
+	if oe.exporter != "" {
+		c.traceExporter = oe.exporter
+	}
+
+	if oe.filePath != "" {
+		c.traceFile.Path = oe.filePath
+	}
+
+	if oe.protocol != "" {
+		c.traceProtocol = oe.protocol
+	}
+
+	if c.otelURL == "" {
+		c.otelURL = oe.tracesEndpoint(c.traceProtocol)
+	}
+
+	if oe.timeout != 0 {
+		c.traceTimeout = oe.timeout
+	}
+
+	if oe.compression != "" {
+		c.compression = oe.compression
+	}
+
+	for _, f := range []struct{ value, env *string }{
+		{&c.traceTLS.CAFile, &oe.tls.CAFile},
+		{&c.traceTLS.CertFile, &oe.tls.CertFile},
+		{&c.traceTLS.KeyFile, &oe.tls.KeyFile},
+	} {
+		if *f.env != "" {
+			*f.value = *f.env
+		}
+	}
+	c.resourceAttrs = oe.resourceAttrs
+	c.tracingDisabled = oe.disabled
+	c.warnings = oe.warnings
+
+	if oe.propagator != nil {
+		c.propagator = oe.propagator
+	}
+
+	if oe.sampler != nil {
+		c.sampler = oe.sampler
+	}
+
+	if oe.baggageFields != nil {
+		c.baggageFields = oe.baggageFields
+	}
+
+	if oe.errorStatusSeverity != "" {
+		c.errorStatusSeverity = oe.errorStatusSeverity
+	}
+}
+
 // CreateConfig creates a new Configuration instance populated with the provided parameters.
 // This is intended to be used for when you want to create a config without loading from environment variables.
 // The Configuration returned satisfies the Configurator interface, allowing it to be used interchangeably with configurations
@@ -83,11 +245,22 @@ func CreateConfig(logLevel slog.Level, otelURL, dbConStr, serviceName string, tr
 	return &Configuration{
 		logLevel:    logLevel,
 		otelURL:     otelURL,
-		strLevel:    logLevel.String(),
+		strLevel:    LevelName(logLevel),
 		dbConStr:    dbConStr,
 		serviceName: serviceName,
 		trimModules: trimModules,
 		trimPaths:   trimPaths,
+
+		serviceVersion: buildVersion(),
+
+		sources: map[string]ConfigValue{
+			"log.level":        {Source: SourceCode},
+			"tracing.url":      {Source: SourceCode},
+			"db.constr":        {Source: SourceCode},
+			"service.name":     {Source: SourceCode},
+			"log.trim_modules": {Source: SourceCode},
+			"log.trim_paths":   {Source: SourceCode},
+		},
 	}
 }
 
@@ -126,3 +299,27 @@ func (c *Configuration) TrimPaths() []string {
 func (c *Configuration) TrimModules() []string {
 	return c.trimModules
 }
+
+// Environment returns the configured deployment environment, such as production.
+// This method is part of the IdentityConfigurator interface.
+func (c *Configuration) Environment() string {
+	return c.environment
+}
+
+// ServiceVersion returns the configured version of the service, which defaults to the version from the build info.
+// This method is part of the IdentityConfigurator interface.
+func (c *Configuration) ServiceVersion() string {
+	return c.serviceVersion
+}
+
+// ServiceNamespace returns the configured namespace of the service.
+// This method is part of the IdentityConfigurator interface.
+func (c *Configuration) ServiceNamespace() string {
+	return c.serviceNamespace
+}
+
+// InstanceID returns the configured ID of this instance of the service.
+// This method is part of the IdentityConfigurator interface.
+func (c *Configuration) InstanceID() string {
+	return c.instanceID
+}
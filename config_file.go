@@ -0,0 +1,667 @@
+package go11y
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv is the environment variable LoadConfig reads the path of a configuration file from
+const ConfigFileEnv = "GO11Y_CONFIG"
+
+// fileConfig is the layout of a YAML or JSON configuration file
+type fileConfig struct {
+	Environment string `yaml:"environment" json:"environment"`
+
+	Service struct {
+		Name       string `yaml:"name" json:"name"`
+		Version    string `yaml:"version" json:"version"`
+		Namespace  string `yaml:"namespace" json:"namespace"`
+		InstanceID string `yaml:"instance_id" json:"instance_id"`
+	} `yaml:"service" json:"service"`
+
+	Log struct {
+		Level       string            `yaml:"level" json:"level"`
+		Levels      map[string]string `yaml:"levels" json:"levels"`
+		Format      string            `yaml:"format" json:"format"`
+		Sinks       []SinkConfig      `yaml:"sinks" json:"sinks"`
+		TrimModules []string          `yaml:"trim_modules" json:"trim_modules"`
+		TrimPaths   []string          `yaml:"trim_paths" json:"trim_paths"`
+	} `yaml:"log" json:"log"`
+
+	Tracing struct {
+		URL      string `yaml:"url" json:"url"`
+		Exporter string `yaml:"exporter" json:"exporter"`
+		File     struct {
+			Path       string `yaml:"path" json:"path"`
+			MaxSizeMB  int    `yaml:"max_size_mb" json:"max_size_mb"`
+			MaxBackups int    `yaml:"max_backups" json:"max_backups"`
+		} `yaml:"file" json:"file"`
+		Protocol    string            `yaml:"protocol" json:"protocol"`
+		Headers     map[string]string `yaml:"headers" json:"headers"`
+		Timeout     string            `yaml:"timeout" json:"timeout"`
+		Compression string            `yaml:"compression" json:"compression"`
+		TLS         struct {
+			CAFile     string `yaml:"ca_file" json:"ca_file"`
+			CertFile   string `yaml:"cert_file" json:"cert_file"`
+			KeyFile    string `yaml:"key_file" json:"key_file"`
+			SkipVerify bool   `yaml:"skip_verify" json:"skip_verify"`
+		} `yaml:"tls" json:"tls"`
+		Sampling struct {
+			Sampler string             `yaml:"sampler" json:"sampler"`
+			Ratio   *float64           `yaml:"ratio" json:"ratio"`
+			Rules   []fileSamplingRule `yaml:"rules" json:"rules"`
+		} `yaml:"sampling" json:"sampling"`
+		TailSampling        *fileTailSampling `yaml:"tail_sampling" json:"tail_sampling"`
+		Propagators         []string          `yaml:"propagators" json:"propagators"`
+		BaggageFields       []string          `yaml:"baggage_fields" json:"baggage_fields"`
+		ErrorStatusSeverity string            `yaml:"error_status_severity" json:"error_status_severity"`
+	} `yaml:"tracing" json:"tracing"`
+
+	Redaction struct {
+		Defaults *bool               `yaml:"defaults" json:"defaults"`
+		Patterns bool                `yaml:"patterns" json:"patterns"`
+		Rules    []fileRedactionRule `yaml:"rules" json:"rules"`
+	} `yaml:"redaction" json:"redaction"`
+
+	DB struct {
+		ConStr       string `yaml:"constr" json:"constr"`
+		Host         string `yaml:"host" json:"host"`
+		Port         int    `yaml:"port" json:"port"`
+		Name         string `yaml:"name" json:"name"`
+		User         string `yaml:"user" json:"user"`
+		PasswordFile string `yaml:"password_file" json:"password_file"`
+		SSLMode      string `yaml:"sslmode" json:"sslmode"`
+		Migrate      *bool  `yaml:"migrate" json:"migrate"`
+	} `yaml:"db" json:"db"`
+
+	env        map[string]string // the environment variables that override the values, keyed by the path of the value
+	dbPassword string            // the database password from DB_PASSWORD, which takes precedence over db.password_file
+	errs       []error           // the problems reading the secrets from the environment variables
+}
+
+type fileRedactionRule struct {
+	Key    string `yaml:"key" json:"key"`
+	Value  string `yaml:"value" json:"value"`
+	Action string `yaml:"action" json:"action"`
+	Keep   int    `yaml:"keep" json:"keep"`
+}
+
+type fileSamplingRule struct {
+	Name       string            `yaml:"name" json:"name"`
+	Route      string            `yaml:"route" json:"route"`
+	Method     string            `yaml:"method" json:"method"`
+	Attributes map[string]string `yaml:"attributes" json:"attributes"`
+	Ratio      *float64          `yaml:"ratio" json:"ratio"`
+}
+
+type fileTailSampling struct {
+	Window           string            `yaml:"window" json:"window"`
+	Latency          string            `yaml:"latency" json:"latency"`
+	Attributes       map[string]string `yaml:"attributes" json:"attributes"`
+	Ratio            float64           `yaml:"ratio" json:"ratio"`
+	MaxTraces        int               `yaml:"max_traces" json:"max_traces"`
+	MaxSpansPerTrace int               `yaml:"max_spans_per_trace" json:"max_spans_per_trace"`
+}
+
+// optionsProvider is implemented by configurations that set more than the Configurator interface covers, such as those
+// loaded from a file. New applies the options before its own.
+type optionsProvider interface {
+	options() []Option
+}
+
+// LoadConfigFile loads the configuration from a YAML (.yaml or .yml) or JSON (.json) file, and then overrides it with
+// any of the environment variables read by LoadConfig that are set. Unknown keys are rejected, and all the problems
+// with the values are returned together, each prefixed with where the value came from.
+func LoadConfigFile(path string) (cfg *Configuration, fault error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	fc := fileConfig{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+
+		if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("could not parse config file '%s': %w", path, err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("could not parse config file '%s': %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("could not load config file '%s': the extension must be .yaml, .yml or .json", path)
+	}
+
+	fc.applyEnv()
+
+	oe, envErr := loadOTelEnv()
+
+	cfg, err = fc.configuration()
+	if err != nil || envErr != nil {
+		return nil, fmt.Errorf("invalid config file '%s':\n%w", path, errors.Join(err, envErr))
+	}
+
+	if _, ok := fc.env["tracing.url"]; ok {
+		oe.endpoint = "" // OTEL_URL takes precedence over the OTLP endpoint variables
+	}
+	if oe.endpoint != "" {
+		cfg.otelURL = "" // the OTLP endpoint variables take precedence over the file
+	}
+
+	cfg.applyOTelEnv(oe)
+	cfg.path = path
+
+	if cfg.traceExporter == ExporterFile && cfg.traceFile.Path == "" {
+		return nil, fmt.Errorf("invalid config file '%s':\ntracing.file.path: is required for the %s exporter", path, ExporterFile)
+	}
+
+	if cfg.serviceVersion == "" {
+		cfg.serviceVersion = buildVersion()
+	}
+
+	cfg.sources = envSources(path, fileKeys(data, filepath.Ext(path)))
+
+	return cfg, nil
+}
+
+// fileKeyAliases maps the keys of a configuration file onto the keys of the effective configuration they set
+var fileKeyAliases = map[string]string{
+	"tracing.sampling":                          "tracing.sampler",
+	"tracing.sampling.ratio":                    "tracing.sampler",
+	"tracing.sampling.sampler":                  "tracing.sampler",
+	"tracing.sampling.rules":                    "tracing.sampler",
+	"tracing.tail_sampling.window":              "tracing.tail_sampling",
+	"tracing.tail_sampling.latency":             "tracing.tail_sampling",
+	"tracing.tail_sampling.attributes":          "tracing.tail_sampling",
+	"tracing.tail_sampling.ratio":               "tracing.tail_sampling",
+	"tracing.tail_sampling.max_traces":          "tracing.tail_sampling",
+	"tracing.tail_sampling.max_spans_per_trace": "tracing.tail_sampling",
+	"redaction.defaults":                        "redaction.rules",
+	"redaction.patterns":                        "redaction.rules",
+	"tracing.file.max_size_mb":                  "tracing.file.max_size",
+	"db.host":                                   "db.constr",
+	"db.port":                                   "db.constr",
+	"db.name":                                   "db.constr",
+	"db.user":                                   "db.constr",
+	"db.password_file":                          "db.constr",
+	"db.sslmode":                                "db.constr",
+}
+
+// fileKeys returns the keys of the effective configuration that are set in the contents of a configuration file
+func fileKeys(data []byte, ext string) map[string]bool {
+	raw := map[string]any{}
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		_ = json.Unmarshal(data, &raw)
+	default:
+		_ = yaml.Unmarshal(data, &raw)
+	}
+
+	keys := map[string]bool{}
+
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			key := prefix + k
+			if alias, ok := fileKeyAliases[key]; ok {
+				key = alias
+			}
+			keys[key] = true
+
+			if child, ok := v.(map[string]any); ok && key != "log.levels" && key != "tracing.headers" {
+				walk(prefix+k+".", child)
+			}
+		}
+	}
+	walk("", raw)
+
+	return keys
+}
+
+// applyEnv overrides the values from the file with the environment variables read by LoadConfig that are set
+func (fc *fileConfig) applyEnv() {
+	fc.env = map[string]string{}
+
+	lookup := func(name, path string) (value string, ok bool) {
+		value, ok = os.LookupEnv(name)
+		if ok {
+			fc.env[path] = name
+		}
+
+		return value, ok
+	}
+
+	if v, ok := lookup("LOG_LEVEL", "log.level"); ok {
+		fc.Log.Level = v
+	}
+
+	if v, ok := lookup("OTEL_URL", "tracing.url"); ok {
+		fc.Tracing.URL = v
+	}
+
+	secret := func(name, path string) (value string, ok bool) {
+		value, source, err := lookupSecret(name)
+		if err != nil {
+			fc.errs = append(fc.errs, err)
+		}
+
+		if source != "" {
+			fc.env[path] = source
+		}
+
+		return value, value != ""
+	}
+
+	if v, ok := secret("DB_CONSTR", "db.constr"); ok {
+		fc.DB.ConStr = v
+	}
+
+	for name, field := range map[string]*string{
+		"DB_HOST":    &fc.DB.Host,
+		"DB_NAME":    &fc.DB.Name,
+		"DB_USER":    &fc.DB.User,
+		"DB_SSLMODE": &fc.DB.SSLMode,
+	} {
+		if v, ok := lookup(name, "db."+strings.ToLower(strings.TrimPrefix(name, "DB_"))); ok {
+			*field = v
+		}
+	}
+
+	if v, ok := lookup("DB_PORT", "db.port"); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			fc.errs = append(fc.errs, fmt.Errorf("db.port (from DB_PORT): must be a port number, got '%s'", v))
+		}
+		fc.DB.Port = port
+	}
+
+	if v, ok := secret("DB_PASSWORD", "db.password_file"); ok {
+		fc.dbPassword = v
+	}
+
+	if v, ok := lookup("OTEL_SERVICE_NAME", "service.name"); ok {
+		fc.Service.Name = v
+	}
+
+	if v, ok := lookup("DEPLOYMENT_ENVIRONMENT", "environment"); ok {
+		fc.Environment = v
+	}
+
+	if v, ok := lookup("SERVICE_VERSION", "service.version"); ok {
+		fc.Service.Version = v
+	}
+
+	if v, ok := lookup("SERVICE_NAMESPACE", "service.namespace"); ok {
+		fc.Service.Namespace = v
+	}
+
+	if v, ok := lookup("SERVICE_INSTANCE_ID", "service.instance_id"); ok {
+		fc.Service.InstanceID = v
+	}
+
+	if v, ok := lookup("TRIM_MODULES", "log.trim_modules"); ok {
+		fc.Log.TrimModules = strings.Split(v, ",")
+	}
+
+	if v, ok := os.LookupEnv("TRIM_PATHS"); ok && v != "" {
+		fc.env["log.trim_paths"] = "TRIM_PATHS"
+		fc.Log.TrimPaths = strings.Split(v, ",")
+	}
+}
+
+// source returns the path of the value, and the environment variable that set it if there is one
+func (fc *fileConfig) source(path string) string {
+	if name, ok := fc.env[path]; ok {
+		return fmt.Sprintf("%s (from %s)", path, name)
+	}
+
+	return path
+}
+
+// configuration validates the values and converts them to a Configuration
+func (fc *fileConfig) configuration() (cfg *Configuration, fault error) {
+	var errs []error
+
+	level := LevelDebug
+	if fc.Log.Level != "" {
+		l, err := ParseLevelStrict(fc.Log.Level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fc.source("log.level"), err))
+		}
+		level = l
+	}
+
+	packageLevels := map[string]slog.Level{}
+	for pkg, name := range fc.Log.Levels {
+		if pkg == "" {
+			errs = append(errs, errors.New("log.levels: the package must not be empty"))
+			continue
+		}
+
+		l, err := ParseLevelStrict(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log.levels.%s: %w", pkg, err))
+			continue
+		}
+		packageLevels[pkg] = l
+	}
+
+	if err := validateFormat(fc.Log.Format); err != nil {
+		errs = append(errs, fmt.Errorf("log.format: %w", err))
+	}
+
+	for i, sink := range fc.Log.Sinks {
+		errs = append(errs, sink.validate(fmt.Sprintf("log.sinks[%d]", i))...)
+	}
+
+	if fc.Tracing.URL != "" {
+		u, err := url.Parse(fc.Tracing.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: must be an http or https URL, got '%s'", fc.source("tracing.url"), fc.Tracing.URL))
+		}
+	}
+
+	if err := validateExporter(fc.Tracing.Exporter); err != nil {
+		errs = append(errs, fmt.Errorf("tracing.exporter: %w", err))
+	}
+
+	if fc.Tracing.File.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("tracing.file.max_size_mb: must not be negative, got %d", fc.Tracing.File.MaxSizeMB))
+	}
+
+	if fc.Tracing.File.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("tracing.file.max_backups: must not be negative, got %d", fc.Tracing.File.MaxBackups))
+	}
+
+	if fc.Tracing.Protocol != "" {
+		if err := validateProtocol(fc.Tracing.Protocol); err != nil {
+			errs = append(errs, fmt.Errorf("tracing.protocol: %w", err))
+		}
+	}
+
+	var timeout time.Duration
+	if fc.Tracing.Timeout != "" {
+		t, err := time.ParseDuration(fc.Tracing.Timeout)
+		if err != nil || t < 0 {
+			errs = append(errs, fmt.Errorf("tracing.timeout: must be a duration such as 5s, got '%s'", fc.Tracing.Timeout))
+		}
+		timeout = t
+	}
+
+	switch fc.Tracing.Compression {
+	case "", CompressionGzip, CompressionNone:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.compression: must be %s or %s, got '%s'", CompressionGzip, CompressionNone, fc.Tracing.Compression))
+	}
+
+	tlsCfg := TLSConfig(fc.Tracing.TLS)
+	errs = append(errs, tlsCfg.validate(func(field string) string { return "tracing.tls." + field })...)
+
+	sampler, err := fc.sampler()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	propagator, err := NewPropagator(fc.Tracing.Propagators...)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("tracing.propagators: %w", err))
+	}
+
+	if err := validateBaggageFields(fc.Tracing.BaggageFields); err != nil {
+		errs = append(errs, fmt.Errorf("tracing.baggage_fields: %w", err))
+	}
+
+	if fc.Tracing.ErrorStatusSeverity != "" {
+		if err := validateSeverity(fc.Tracing.ErrorStatusSeverity); err != nil {
+			errs = append(errs, fmt.Errorf("tracing.error_status_severity: %w", err))
+		}
+	}
+
+	var tailSampling *TailSamplingConfig
+	if ts := fc.Tracing.TailSampling; ts != nil {
+		tailSampling = &TailSamplingConfig{Attributes: ts.Attributes, Ratio: ts.Ratio, MaxTraces: ts.MaxTraces, MaxSpansPerTrace: ts.MaxSpansPerTrace}
+
+		for _, d := range []struct {
+			field string
+			value string
+			dst   *time.Duration
+		}{{"window", ts.Window, &tailSampling.Window}, {"latency", ts.Latency, &tailSampling.Latency}} {
+			if d.value == "" {
+				continue
+			}
+
+			v, err := time.ParseDuration(d.value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tracing.tail_sampling.%s: must be a duration such as 5s, got '%s'", d.field, d.value))
+			}
+			*d.dst = v
+		}
+
+		errs = append(errs, tailSampling.validate(func(field string) string { return "tracing.tail_sampling." + field })...)
+	}
+
+	rules := []RedactionRule{}
+	if fc.Redaction.Defaults == nil || *fc.Redaction.Defaults {
+		rules = append(rules, DefaultRedactionRules()...)
+	}
+
+	if fc.Redaction.Patterns {
+		rules = append(rules, PatternRedactionRules()...)
+	}
+
+	for _, r := range fc.Redaction.Rules {
+		rules = append(rules, RedactionRule{Key: r.Key, Value: r.Value, Action: RedactionAction(r.Action), Keep: r.Keep})
+	}
+
+	if _, err := compileRedactionRules(rules); err != nil {
+		errs = append(errs, fmt.Errorf("redaction.rules: %w", err))
+	}
+
+	errs = append(errs, fc.errs...)
+
+	dbConStr := fc.DB.ConStr
+	if dbConStr == "" {
+		port := ""
+		if fc.DB.Port != 0 {
+			port = strconv.Itoa(fc.DB.Port)
+		}
+
+		d := dbSettings{
+			host:     fc.DB.Host,
+			port:     port,
+			name:     fc.DB.Name,
+			user:     fc.DB.User,
+			password: fc.dbPassword,
+			sslMode:  fc.DB.SSLMode,
+		}
+
+		if d.password == "" && fc.DB.PasswordFile != "" {
+			password, err := readSecret(fc.DB.PasswordFile)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("db.password_file: %w", err))
+			}
+			d.password = password
+		}
+
+		errs = append(errs, d.validate(func(field string) string { return fc.source("db." + field) })...)
+		dbConStr = d.conStr()
+	}
+
+	if dbConStr != "" {
+		if _, err := pgx.ParseConfig(dbConStr); err != nil {
+			errs = append(errs, fmt.Errorf("%s: is not a valid connection string", fc.source("db.constr")))
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	trimPaths := fc.Log.TrimPaths
+	if len(trimPaths) == 0 {
+		path, _ := os.Getwd()
+		trimPaths = []string{path}
+	}
+
+	cfg = CreateConfig(level, fc.Tracing.URL, dbConStr, fc.Service.Name, fc.Log.TrimModules, trimPaths)
+	cfg.environment = fc.Environment
+	cfg.serviceVersion = fc.Service.Version
+	cfg.serviceNamespace = fc.Service.Namespace
+	cfg.instanceID = fc.Service.InstanceID
+	cfg.format = fc.Log.Format
+	cfg.sinks = fc.Log.Sinks
+	cfg.packageLevels = packageLevels
+	cfg.traceHeaders = fc.Tracing.Headers
+	cfg.traceExporter = fc.Tracing.Exporter
+	cfg.traceFile = FileExporterConfig{Path: fc.Tracing.File.Path, MaxSize: int64(fc.Tracing.File.MaxSizeMB) << 20, MaxBackups: fc.Tracing.File.MaxBackups}
+	cfg.traceProtocol = fc.Tracing.Protocol
+	cfg.traceTimeout = timeout
+	cfg.compression = fc.Tracing.Compression
+	cfg.traceTLS = tlsCfg
+	cfg.sampler = sampler
+	cfg.tailSampling = tailSampling
+	if len(fc.Tracing.Propagators) != 0 {
+		cfg.propagator = propagator
+	}
+	if len(fc.Tracing.BaggageFields) != 0 {
+		cfg.baggageFields = fc.Tracing.BaggageFields
+	}
+	cfg.errorStatusSeverity = fc.Tracing.ErrorStatusSeverity
+	cfg.redactionRules = rules
+	cfg.skipMigrations = fc.DB.Migrate != nil && !*fc.DB.Migrate
+
+	return cfg, nil
+}
+
+// sampler creates the sampler from tracing.sampling: the named sampler (parentbased_traceidratio if only the ratio is
+// set), with the rules checked before it if there are any. It returns nil if none of them are set.
+func (fc *fileConfig) sampler() (sampler otelSDKTrace.Sampler, fault error) {
+	sc := fc.Tracing.Sampling
+
+	name, ratio := sc.Sampler, 1.0
+	if sc.Ratio != nil {
+		ratio = *sc.Ratio
+		if name == "" {
+			name = SamplerParentBasedTraceIDRatio
+		}
+	}
+
+	errs := []error{}
+
+	if sc.Ratio != nil && (ratio < 0 || ratio > 1) {
+		errs = append(errs, fmt.Errorf("tracing.sampling.ratio: must be between 0 and 1, got %v", ratio))
+	} else if name != "" {
+		s, err := NewSampler(name, ratio)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tracing.sampling.sampler: %w", err))
+		}
+		sampler = s
+	}
+
+	rules := make([]SamplingRule, len(sc.Rules))
+	for i, r := range sc.Rules {
+		if r.Ratio == nil {
+			errs = append(errs, fmt.Errorf("tracing.sampling.rules[%d].ratio: is required", i))
+			continue
+		}
+
+		rules[i] = SamplingRule{Name: r.Name, Route: r.Route, Method: r.Method, Attributes: r.Attributes, Ratio: *r.Ratio}
+		if err := rules[i].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("tracing.sampling.rules[%d]: %w", i, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if len(rules) != 0 {
+		return NewRuleSampler(sampler, rules...)
+	}
+
+	return sampler, nil
+}
+
+// options returns the options for the settings that the Configurator interface doesn't cover
+func (c *Configuration) options() []Option {
+	opts := []Option{}
+
+	if c.format != "" {
+		opts = append(opts, WithFormat(c.format))
+	}
+
+	if len(c.sinks) != 0 {
+		opts = append(opts, WithSinks(c.sinks...))
+	}
+
+	if len(c.packageLevels) != 0 {
+		opts = append(opts, WithPackageLevels(c.packageLevels))
+	}
+
+	if len(c.traceHeaders) != 0 {
+		opts = append(opts, WithTraceHeaders(c.traceHeaders))
+	}
+
+	if c.sampler != nil {
+		opts = append(opts, WithSampler(c.sampler))
+	}
+
+	if c.tailSampling != nil {
+		opts = append(opts, WithTailSampling(*c.tailSampling))
+	}
+
+	if c.propagator != nil {
+		opts = append(opts, WithPropagator(c.propagator))
+	}
+
+	if len(c.baggageFields) != 0 {
+		opts = append(opts, WithBaggageFields(c.baggageFields...))
+	}
+
+	if c.errorStatusSeverity != "" {
+		opts = append(opts, WithErrorStatusSeverity(c.errorStatusSeverity))
+	}
+
+	opts = append(opts, func(s *settings) {
+		s.traceExporter = c.traceExporter
+		s.traceFile = c.traceFile
+		s.traceProtocol = c.traceProtocol
+		s.traceTimeout = c.traceTimeout
+		s.compression = c.compression
+		s.traceTLS = c.traceTLS
+		s.resourceAttrs = c.resourceAttrs
+		s.tracingDisabled = c.tracingDisabled
+	})
+
+	if c.redactionRules != nil {
+		opts = append(opts, WithRedactionRules(c.redactionRules...))
+	}
+
+	if c.skipMigrations {
+		opts = append(opts, WithoutMigrations())
+	}
+
+	return opts
+}
@@ -0,0 +1,230 @@
+package go11y_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+const validYAML = `
+service:
+  name: checkout
+log:
+  level: info
+  levels:
+    github.com/jsnfwlr/go11y_test: warn
+  format: text
+tracing:
+  url: http://localhost:4318/v1/traces
+  headers:
+    authorization: Bearer abc
+  sampling:
+    ratio: 0.25
+redaction:
+  rules:
+    - key: "*secret*"
+db:
+  migrate: false
+`
+
+const validJSON = `{
+  "service": {"name": "checkout"},
+  "log": {"level": "info", "format": "json"},
+  "tracing": {"url": "http://localhost:4318/v1/traces"}
+}`
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		contents string
+		env      map[string]string
+		expLevel string
+		expURL   string
+		expName  string
+		expErrs  []string
+	}{
+		{
+			name:     "yaml",
+			file:     "go11y.yaml",
+			contents: validYAML,
+			expLevel: "INFO",
+			expURL:   "http://localhost:4318/v1/traces",
+			expName:  "checkout",
+		},
+		{
+			name:     "json",
+			file:     "go11y.json",
+			contents: validJSON,
+			expLevel: "INFO",
+			expURL:   "http://localhost:4318/v1/traces",
+			expName:  "checkout",
+		},
+		{
+			name:     "env overrides file",
+			file:     "go11y.yml",
+			contents: validYAML,
+			env:      map[string]string{"LOG_LEVEL": "error", "OTEL_SERVICE_NAME": "payments"},
+			expLevel: "ERR",
+			expURL:   "http://localhost:4318/v1/traces",
+			expName:  "payments",
+		},
+		{
+			name:     "unknown key",
+			file:     "go11y.yaml",
+			contents: "log:\n  levle: info\n",
+			expErrs:  []string{"field levle not found"},
+		},
+		{
+			name:     "unsupported extension",
+			file:     "go11y.toml",
+			contents: "",
+			expErrs:  []string{"the extension must be .yaml, .yml or .json"},
+		},
+		{
+			name: "aggregated errors",
+			file: "go11y.yaml",
+			contents: `
+log:
+  level: verbose
+  format: xml
+  sinks:
+    - type: file
+    - type: kafka
+tracing:
+  url: localhost:4318
+  sampling:
+    ratio: 2
+redaction:
+  rules:
+    - key: "*"
+      action: scramble
+`,
+			expErrs: []string{
+				"log.level:",
+				"log.format: must be json or text, got 'xml'",
+				"log.sinks[0].path: is required for a file sink",
+				"log.sinks[1].type: must be one of stdout, stderr, file or syslog, got 'kafka'",
+				"tracing.url: must be an http or https URL",
+				"tracing.sampling.ratio: must be between 0 and 1, got 2",
+				"redaction.rules:",
+			},
+		},
+		{
+			name:     "invalid env override",
+			file:     "go11y.yaml",
+			contents: validYAML,
+			env:      map[string]string{"LOG_LEVEL": "loud"},
+			expErrs:  []string{"log.level (from LOG_LEVEL):"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := go11y.LoadConfigFile(writeConfigFile(t, tc.file, tc.contents))
+
+			if len(tc.expErrs) != 0 {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+
+				for _, e := range tc.expErrs {
+					if !strings.Contains(err.Error(), e) {
+						t.Errorf("expected the error to contain %q, got:\n%v", e, err)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to load config file: %v", err)
+			}
+
+			if got := go11y.LevelName(cfg.LogLevel()); got != tc.expLevel {
+				t.Errorf("expected level %s, got %s", tc.expLevel, got)
+			}
+
+			if cfg.URL() != tc.expURL {
+				t.Errorf("expected url %s, got %s", tc.expURL, cfg.URL())
+			}
+
+			if cfg.ServiceName() != tc.expName {
+				t.Errorf("expected service name %s, got %s", tc.expName, cfg.ServiceName())
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromEnvFile(t *testing.T) {
+	t.Setenv(go11y.ConfigFileEnv, writeConfigFile(t, "go11y.json", validJSON))
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.ServiceName() != "checkout" {
+		t.Errorf("expected the service name from the file, got %s", cfg.ServiceName())
+	}
+}
+
+func TestNewWithConfigFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "go11y.log")
+
+	cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", `
+log:
+  level: debug
+  levels:
+    github.com/jsnfwlr/go11y_test: warn
+  sinks:
+    - type: file
+      format: text
+      path: `+logFile+`
+`))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	o.Info("below the package level")
+	o.Warning("at the package level")
+	o.Close()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("could not read log file: %v", err)
+	}
+
+	lines := withoutTracingMode(string(data))
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line, got %d: %s", len(lines), data)
+	}
+
+	if !strings.Contains(lines[0], `msg="at the package level"`) {
+		t.Errorf("expected a text record for the warning, got %s", lines[0])
+	}
+}
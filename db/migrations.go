@@ -86,6 +86,12 @@ func NewMigrator(ctx context.Context, logger Logger, connParams Configurator, fs
 		return DBMigrator{}, fmt.Errorf("could not connect to database: %w", err)
 	}
 
+	return NewMigratorWithConn(ctx, logger, connParams, conn, fs)
+}
+
+// NewMigratorWithConn creates a migrator that uses an existing connection, such as one acquired from a pool, instead of
+// connecting with the connection string from connParams
+func NewMigratorWithConn(ctx context.Context, logger Logger, connParams Configurator, conn *pgx.Conn, fs FilesystemProvider) (migrator DBMigrator, fault error) {
 	mo := &migrate.MigratorOptions{
 		DisableTx: false,
 	}
@@ -109,8 +115,10 @@ func NewMigrator(ctx context.Context, logger Logger, connParams Configurator, fs
 	}, nil
 }
 
+// Info describes the database and its migrations. The password in DBConnStr is masked.
 type Info struct {
 	DBConnStr  string
+	Host       string
 	Port       string
 	Database   string
 	Migrations MigrationInfo
@@ -136,8 +144,13 @@ func ErrInvalidSequenceNumber(seq int32) error {
 func (m DBMigrator) Info(stopAfter int32) (information Info, fault error) {
 	var err error
 
+	cc := m.connection.Config()
+
 	i := Info{
-		DBConnStr:  m.configuration.DBConStr(),
+		DBConnStr:  MaskDSN(cc.ConnString()),
+		Host:       cc.Host,
+		Port:       fmt.Sprintf("%d", cc.Port),
+		Database:   cc.Database,
 		Migrations: MigrationInfo{},
 	}
 
@@ -222,7 +235,7 @@ func RunMigrations(ctx context.Context, logger Logger, connParams Configurator,
 	}
 
 	if printSummary {
-		fmt.Printf("Migrations for %s:%s/%s\n", info.DBConnStr, info.Port, info.Database)
+		fmt.Printf("Migrations for %s:%s/%s\n", info.Host, info.Port, info.Database)
 		fmt.Println(info.Migrations.Summary)
 	}
 
@@ -0,0 +1,129 @@
+package go11y
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// lookupSecret returns the value of the environment variable or, if it isn't set, the contents of the file named by the
+// environment variable with _FILE added to its name (such as a Docker or Kubernetes secret), without the trailing
+// newline. The source is the name of the environment variable the value came from.
+func lookupSecret(name string) (value, source string, fault error) {
+	if v, ok := os.LookupEnv(name); ok && v != "" {
+		return v, name, nil
+	}
+
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok || path == "" {
+		return "", "", nil
+	}
+
+	v, err := readSecret(path)
+	if err != nil {
+		return "", name + "_FILE", fmt.Errorf("%s_FILE: %w", name, err)
+	}
+
+	return v, name + "_FILE", nil
+}
+
+// readSecret reads a secret from the file, without the trailing newline
+func readSecret(path string) (value string, fault error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read the secret: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// The SSL modes a database connection can use
+var dbSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// dbSettings are the parts of a database connection string, for when it isn't provided in full
+type dbSettings struct {
+	host     string
+	port     string
+	name     string
+	user     string
+	password string
+	sslMode  string
+}
+
+// validate returns the problems with the settings, using source to describe where each one came from
+func (d dbSettings) validate(source func(field string) string) (errs []error) {
+	if d.host == "" && (d.port != "" || d.name != "" || d.user != "" || d.password != "" || d.sslMode != "") {
+		errs = append(errs, fmt.Errorf("%s: is required when any of the other database settings are set", source("host")))
+	}
+
+	if d.port != "" {
+		if p, err := strconv.Atoi(d.port); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("%s: must be a port number, got '%s'", source("port"), d.port))
+		}
+	}
+
+	if d.sslMode != "" && !slices.Contains(dbSSLModes, d.sslMode) {
+		errs = append(errs, fmt.Errorf("%s: must be one of %s, got '%s'", source("sslmode"), strings.Join(dbSSLModes, ", "), d.sslMode))
+	}
+
+	return errs
+}
+
+// conStr builds the connection string from the settings, or returns an empty string if the host isn't set
+func (d dbSettings) conStr() string {
+	if d.host == "" {
+		return ""
+	}
+
+	u := url.URL{Scheme: "postgres", Host: d.host, Path: "/" + d.name}
+	if d.port != "" {
+		u.Host = net.JoinHostPort(d.host, d.port)
+	}
+
+	switch {
+	case d.user != "" && d.password != "":
+		u.User = url.UserPassword(d.user, d.password)
+	case d.user != "":
+		u.User = url.User(d.user)
+	}
+
+	if d.sslMode != "" {
+		u.RawQuery = url.Values{"sslmode": {d.sslMode}}.Encode()
+	}
+
+	return u.String()
+}
+
+// loadDBEnv reads the database connection string from DB_CONSTR (or DB_CONSTR_FILE) or, if it isn't set, builds it from
+// DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD (or DB_PASSWORD_FILE) and DB_SSLMODE
+func loadDBEnv() (conStr string, fault error) {
+	conStr, _, err := lookupSecret("DB_CONSTR")
+	if err != nil || conStr != "" {
+		return conStr, err
+	}
+
+	password, _, err := lookupSecret("DB_PASSWORD")
+	if err != nil {
+		return "", err
+	}
+
+	d := dbSettings{
+		host:     os.Getenv("DB_HOST"),
+		port:     os.Getenv("DB_PORT"),
+		name:     os.Getenv("DB_NAME"),
+		user:     os.Getenv("DB_USER"),
+		password: password,
+		sslMode:  os.Getenv("DB_SSLMODE"),
+	}
+
+	if errs := d.validate(func(field string) string { return "DB_" + strings.ToUpper(field) }); len(errs) != 0 {
+		return "", errors.Join(errs...)
+	}
+
+	return d.conStr(), nil
+}
@@ -0,0 +1,145 @@
+package go11y_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+func writeSecret(t *testing.T, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+		t.Fatalf("could not write secret: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfigDB(t *testing.T) {
+	testCases := []struct {
+		name      string
+		env       map[string]string
+		expConStr string
+		expErrs   []string
+	}{
+		{
+			name:      "connection string",
+			env:       map[string]string{"DB_CONSTR": "postgres://go11y:hunter2@db:5432/go11y"},
+			expConStr: "postgres://go11y:hunter2@db:5432/go11y",
+		},
+		{
+			name:      "connection string file",
+			env:       map[string]string{"DB_CONSTR_FILE": writeSecret(t, "postgres://go11y:hunter2@db:5432/go11y")},
+			expConStr: "postgres://go11y:hunter2@db:5432/go11y",
+		},
+		{
+			name: "settings",
+			env: map[string]string{
+				"DB_HOST":          "db",
+				"DB_PORT":          "5433",
+				"DB_NAME":          "go11y",
+				"DB_USER":          "go11y",
+				"DB_PASSWORD_FILE": writeSecret(t, "hunter 2@"),
+				"DB_SSLMODE":       "require",
+			},
+			expConStr: "postgres://go11y:hunter%202%40@db:5433/go11y?sslmode=require",
+		},
+		{
+			name: "connection string takes precedence",
+			env: map[string]string{
+				"DB_CONSTR": "postgres://go11y@db/go11y",
+				"DB_HOST":   "other",
+			},
+			expConStr: "postgres://go11y@db/go11y",
+		},
+		{
+			name:    "missing secret file",
+			env:     map[string]string{"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing"), "DB_HOST": "db"},
+			expErrs: []string{"DB_PASSWORD_FILE"},
+		},
+		{
+			name:    "invalid settings",
+			env:     map[string]string{"DB_HOST": "db", "DB_PORT": "http", "DB_SSLMODE": "always"},
+			expErrs: []string{"DB_PORT", "DB_SSLMODE"},
+		},
+		{
+			name:    "settings without a host",
+			env:     map[string]string{"DB_NAME": "go11y"},
+			expErrs: []string{"DB_HOST"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := go11y.LoadConfig()
+
+			if len(tc.expErrs) != 0 {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+
+				for _, e := range tc.expErrs {
+					if !strings.Contains(err.Error(), e) {
+						t.Errorf("expected the error to mention %s, got %v", e, err)
+					}
+				}
+
+				if strings.Contains(err.Error(), "hunter") {
+					t.Errorf("expected the error not to contain the password, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if cfg.DBConStr() != tc.expConStr {
+				t.Errorf("expected the connection string to be %s, got %s", tc.expConStr, cfg.DBConStr())
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileDB(t *testing.T) {
+	cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", `
+db:
+  host: db
+  port: 5433
+  name: go11y
+  user: go11y
+  password_file: `+writeSecret(t, "hunter2")+`
+  sslmode: disable
+  migrate: false
+`))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	expected := "postgres://go11y:hunter2@db:5433/go11y?sslmode=disable"
+	if cfg.DBConStr() != expected {
+		t.Errorf("expected the connection string to be %s, got %s", expected, cfg.DBConStr())
+	}
+
+	t.Setenv("DB_PASSWORD", "from-env")
+
+	cfg, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "db:\n  host: db\n  user: go11y\n  password_file: /does/not/matter\n"))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	expected = "postgres://go11y:from-env@db/"
+	if cfg.DBConStr() != expected {
+		t.Errorf("expected the password from the environment, got %s", cfg.DBConStr())
+	}
+}
@@ -0,0 +1,409 @@
+package go11y
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	otelExportTrace "go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	otelExportTraceGRPC "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelExportTraceHTTP "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	collectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	protoTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// defaultExportTimeout is how long each export can take if the timeout isn't set
+const defaultExportTimeout = 10 * time.Second
+
+// ExporterConfig configures how the spans are exported
+type ExporterConfig struct {
+	Exporter    string             // ExporterOTLP (the default), ExporterStdout or ExporterFile
+	File        FileExporterConfig // the file the spans are written to, for ExporterFile
+	Protocol    string             // ProtocolHTTPProtobuf (the default), ProtocolHTTPJSON or ProtocolGRPC
+	Headers     map[string]string  // sent with every export, such as an API key
+	Timeout     time.Duration      // how long each export can take, 10 seconds if it is zero
+	Compression string             // CompressionGzip (the default) or CompressionNone
+	TLS         TLSConfig
+}
+
+// TLSConfig configures the TLS connection to the collector, which is used when the OpenTelemetry URL is https
+type TLSConfig struct {
+	CAFile     string // the PEM encoded certificates to verify the collector with, instead of the system's
+	CertFile   string // the PEM encoded client certificate, for mutual TLS
+	KeyFile    string // the PEM encoded key of the client certificate
+	SkipVerify bool   // don't verify the collector's certificate, which should only be used for testing
+}
+
+// ExporterConfigurator is implemented by Configurators that configure how the spans are exported. It is separate from
+// the Configurator interface so existing implementations don't have to change.
+type ExporterConfigurator interface {
+	ExporterConfig() ExporterConfig
+}
+
+// WithExporterConfig overrides how the spans are exported. The headers are only overridden if they are set.
+func WithExporterConfig(ec ExporterConfig) Option {
+	return func(s *settings) {
+		s.traceExporter = ec.Exporter
+		s.traceFile = ec.File
+		s.traceProtocol = ec.Protocol
+		s.traceTimeout = ec.Timeout
+		s.compression = ec.Compression
+		s.traceTLS = ec.TLS
+
+		if ec.Headers != nil {
+			s.traceHeaders = ec.Headers
+		}
+	}
+}
+
+// WithExporterConfig sets how the spans are exported
+func (c *Configuration) WithExporterConfig(ec ExporterConfig) *Configuration {
+	c.traceExporter = ec.Exporter
+	c.traceFile = ec.File
+	c.traceProtocol = ec.Protocol
+	c.traceHeaders = ec.Headers
+	c.traceTimeout = ec.Timeout
+	c.compression = ec.Compression
+	c.traceTLS = ec.TLS
+
+	if c.sources == nil {
+		c.sources = map[string]ConfigValue{}
+	}
+
+	for k, set := range map[string]bool{
+		"tracing.exporter":         ec.Exporter != "",
+		"tracing.file.path":        ec.File.Path != "",
+		"tracing.file.max_size":    ec.File.MaxSize != 0,
+		"tracing.file.max_backups": ec.File.MaxBackups != 0,
+		"tracing.protocol":         ec.Protocol != "",
+		"tracing.headers":          len(ec.Headers) != 0,
+		"tracing.timeout":          ec.Timeout != 0,
+		"tracing.compression":      ec.Compression != "",
+		"tracing.tls.ca_file":      ec.TLS.CAFile != "",
+		"tracing.tls.cert_file":    ec.TLS.CertFile != "",
+		"tracing.tls.key_file":     ec.TLS.KeyFile != "",
+		"tracing.tls.skip_verify":  ec.TLS.SkipVerify,
+	} {
+		if set {
+			c.sources[k] = ConfigValue{Source: SourceCode}
+		}
+	}
+
+	return c
+}
+
+// ExporterConfig returns how the spans are exported.
+// This method is part of the ExporterConfigurator interface.
+func (c *Configuration) ExporterConfig() ExporterConfig {
+	return ExporterConfig{
+		Exporter:    c.traceExporter,
+		File:        c.traceFile,
+		Protocol:    c.traceProtocol,
+		Headers:     c.traceHeaders,
+		Timeout:     c.traceTimeout,
+		Compression: c.compression,
+		TLS:         c.traceTLS,
+	}
+}
+
+// configOptions returns the options for the settings of the configuration that the Configurator interface doesn't
+// cover, which are applied before the options passed to New
+func configOptions(cfg Configurator) []Option {
+	if op, ok := cfg.(optionsProvider); ok {
+		return op.options()
+	}
+
+	if ec, ok := cfg.(ExporterConfigurator); ok {
+		return []Option{WithExporterConfig(ec.ExporterConfig())}
+	}
+
+	return nil
+}
+
+// validate returns the problems with the TLS settings, using source to describe where each one came from
+func (t TLSConfig) validate(source func(field string) string) (errs []error) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("%s and %s: must be set together", source("cert_file"), source("key_file")))
+	}
+
+	return errs
+}
+
+// clientConfig creates the TLS configuration for the connection to the collector, or returns nil if none of the TLS
+// settings are set so the defaults are used
+func (t TLSConfig) clientConfig() (tlsCfg *tls.Config, fault error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	if errs := t.validate(func(field string) string { return field }); len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	tlsCfg = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.SkipVerify, //nolint:gosec // only when it is explicitly configured
+	}
+
+	if t.CAFile != "" {
+		data, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("the CA file '%s' doesn't contain any PEM encoded certificates", t.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// spanExporter creates the exporter selected by the settings, which sends the spans to the OpenTelemetry URL unless
+// they are written to stdout or a file
+func spanExporter(ctx context.Context, cfg Configurator, s *settings) (exporter otelSDKTrace.SpanExporter, fault error) {
+	switch s.traceExporter {
+	case ExporterStdout:
+		return NewStdoutExporter(ctx)
+	case ExporterFile:
+		return NewFileExporter(ctx, s.traceFile)
+	default:
+		return otlpExporter(ctx, cfg, s)
+	}
+}
+
+// validateExporter returns an error if the spans can't be sent to the exporter
+func validateExporter(exporter string) (fault error) {
+	switch exporter {
+	case "", ExporterOTLP, ExporterStdout, ExporterFile:
+		return nil
+	default:
+		return fmt.Errorf("must be %s, %s or %s, got '%s'", ExporterOTLP, ExporterStdout, ExporterFile, exporter)
+	}
+}
+
+// otlpExporter creates an exporter that sends the spans to the OpenTelemetry URL from the configuration with the
+// protocol, headers, timeout, compression and TLS settings from the settings. Gzip compression is used unless it is set
+// to none, and the connection is only secured if the URL is https. There is no default URL, as the spans are only
+// recorded locally when there isn't one (see tracingMode), rather than being sent to a collector that may not exist.
+func otlpExporter(ctx context.Context, cfg Configurator, s *settings) (exporter *otelExportTrace.Exporter, fault error) {
+	if cfg.URL() == "" {
+		return nil, errors.New("there is no OpenTelemetry URL to export the spans to")
+	}
+
+	tlsCfg, err := s.traceTLS.clientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+
+	insecure := !strings.HasPrefix(cfg.URL(), "https://")
+	if tlsCfg != nil && insecure {
+		return nil, fmt.Errorf("TLS is configured, but the URL '%s' is not https", cfg.URL())
+	}
+
+	compress := s.compression != CompressionNone
+
+	switch s.traceProtocol {
+	case ProtocolGRPC:
+		options := []otelExportTraceGRPC.Option{otelExportTraceGRPC.WithEndpointURL(cfg.URL())}
+
+		if len(s.traceHeaders) != 0 {
+			options = append(options, otelExportTraceGRPC.WithHeaders(s.traceHeaders))
+		}
+
+		if s.traceTimeout > 0 {
+			options = append(options, otelExportTraceGRPC.WithTimeout(s.traceTimeout))
+		}
+
+		if compress {
+			options = append(options, otelExportTraceGRPC.WithCompressor(CompressionGzip))
+		}
+
+		switch {
+		case insecure:
+			options = append(options, otelExportTraceGRPC.WithInsecure())
+		case tlsCfg != nil:
+			options = append(options, otelExportTraceGRPC.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+
+		return otelExportTrace.New(ctx, otelExportTraceGRPC.NewClient(options...))
+	case ProtocolHTTPJSON:
+		return otelExportTrace.New(ctx, newJSONClient(cfg.URL(), s, tlsCfg))
+	}
+
+	options := []otelExportTraceHTTP.Option{otelExportTraceHTTP.WithEndpointURL(cfg.URL())}
+
+	if len(s.traceHeaders) != 0 {
+		options = append(options, otelExportTraceHTTP.WithHeaders(s.traceHeaders))
+	}
+
+	if s.traceTimeout > 0 {
+		options = append(options, otelExportTraceHTTP.WithTimeout(s.traceTimeout))
+	}
+
+	if compress {
+		options = append(options, otelExportTraceHTTP.WithCompression(otelExportTraceHTTP.GzipCompression))
+	}
+
+	switch {
+	case insecure:
+		options = append(options, otelExportTraceHTTP.WithInsecure())
+	case tlsCfg != nil:
+		options = append(options, otelExportTraceHTTP.WithTLSClientConfig(tlsCfg))
+	}
+
+	return otelExportTrace.New(ctx, otelExportTraceHTTP.NewClient(options...))
+}
+
+// jsonClient sends the spans to the collector as OTLP/JSON over HTTP, which the OpenTelemetry SDK doesn't provide a
+// client for
+type jsonClient struct {
+	url      string
+	headers  map[string]string
+	timeout  time.Duration
+	compress bool
+	client   *http.Client
+}
+
+// newJSONClient creates a client that sends the spans to the URL
+func newJSONClient(url string, s *settings, tlsCfg *tls.Config) *jsonClient {
+	timeout := s.traceTimeout
+	if timeout <= 0 {
+		timeout = defaultExportTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+
+	return &jsonClient{
+		url:      url,
+		headers:  s.traceHeaders,
+		timeout:  timeout,
+		compress: s.compression != CompressionNone,
+		client:   &http.Client{Transport: transport},
+	}
+}
+
+// Start does nothing, as the connections are made when the spans are uploaded
+func (c *jsonClient) Start(ctx context.Context) (fault error) {
+	return nil
+}
+
+// Stop closes the idle connections to the collector
+func (c *jsonClient) Stop(ctx context.Context) (fault error) {
+	c.client.CloseIdleConnections()
+
+	return nil
+}
+
+// UploadTraces sends the spans to the collector, and returns an error if it doesn't accept them
+func (c *jsonClient) UploadTraces(ctx context.Context, spans []*protoTrace.ResourceSpans) (fault error) {
+	body, err := otlpJSON(spans)
+	if err != nil {
+		return err
+	}
+
+	if c.compress {
+		buf := &bytes.Buffer{}
+		zw := gzip.NewWriter(buf)
+		if _, err := zw.Write(body); err != nil {
+			return fmt.Errorf("could not compress the spans: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("could not compress the spans: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create the export request: %w", err)
+	}
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.compress {
+		req.Header.Set("Content-Encoding", CompressionGzip)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not export the spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("could not export the spans: the collector responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// otlpJSON encodes the spans as an OTLP/JSON export request, which differs from the standard JSON encoding of protobuf
+// messages by using hex for the trace and span IDs and numbers for the enums
+func otlpJSON(spans []*protoTrace.ResourceSpans) (data []byte, fault error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(&collectorTrace.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the spans: %w", err)
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("could not encode the spans: %w", err)
+	}
+
+	var hexIDs func(v any)
+	hexIDs = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if id, ok := child.(string); ok && (k == "traceId" || k == "spanId" || k == "parentSpanId") {
+					if b, err := base64.StdEncoding.DecodeString(id); err == nil {
+						v[k] = hex.EncodeToString(b)
+					}
+					continue
+				}
+				hexIDs(child)
+			}
+		case []any:
+			for _, child := range v {
+				hexIDs(child)
+			}
+		}
+	}
+	hexIDs(raw)
+
+	return json.Marshal(raw)
+}
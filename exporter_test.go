@@ -0,0 +1,478 @@
+package go11y_test
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+	collectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// testCerts are a CA, and a server and client certificate signed by it, for testing the exporters over TLS
+type testCerts struct {
+	caFile   string
+	certFile string // the client certificate
+	keyFile  string // the client key
+	server   tls.Certificate
+	pool     *x509.CertPool
+}
+
+func newTestCerts(t *testing.T) testCerts {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate the CA key: %v", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go11y test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create the CA certificate: %v", err)
+	}
+
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("could not generate a key: %v", err)
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "go11y test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("could not create a certificate: %v", err)
+		}
+
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+
+		return path
+	}
+
+	serverCert, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	server, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("could not load the server certificate: %v", err)
+	}
+
+	clientCert, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return testCerts{
+		caFile:   write("ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+		certFile: write("client.pem", clientCert),
+		keyFile:  write("client-key.pem", clientKey),
+		server:   server,
+		pool:     pool,
+	}
+}
+
+// serverTLS requires the clients to present a certificate signed by the test CA
+func (c testCerts) serverTLS() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{c.server},
+		ClientCAs:    c.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// grpcCollector is an in-process OTLP/gRPC collector that records the requests sent to it
+type grpcCollector struct {
+	collectorTrace.UnimplementedTraceServiceServer
+
+	mu       sync.Mutex
+	headers  []metadata.MD
+	requests []*collectorTrace.ExportTraceServiceRequest
+}
+
+func (c *grpcCollector) Export(ctx context.Context, req *collectorTrace.ExportTraceServiceRequest) (*collectorTrace.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	c.mu.Lock()
+	c.headers = append(c.headers, md)
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	return &collectorTrace.ExportTraceServiceResponse{}, nil
+}
+
+// startGRPCCollector serves the collector on a local port, over TLS if tlsCfg is set, and returns its address
+func startGRPCCollector(t *testing.T, c *grpcCollector, tlsCfg *tls.Config) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+
+	opts := []grpc.ServerOption{}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+
+	srv := grpc.NewServer(opts...)
+	collectorTrace.RegisterTraceServiceServer(srv, c)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// jsonCollector records the requests sent to it by the OTLP/JSON exporter
+type jsonCollector struct {
+	mu       sync.Mutex
+	headers  []http.Header
+	requests []map[string]any
+}
+
+func (c *jsonCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+
+	req := map[string]any{}
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.headers = append(c.headers, r.Header.Clone())
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte("{}"))
+}
+
+// exporterConfigurator is a Configurator that also configures the exporter
+type exporterConfigurator struct {
+	go11y.Configurator
+	ec go11y.ExporterConfig
+}
+
+func (e exporterConfigurator) ExporterConfig() go11y.ExporterConfig {
+	return e.ec
+}
+
+// exportSpan creates an observer with the configuration, and exports a span with it
+func exportSpan(t *testing.T, cfg go11y.Configurator) {
+	t.Helper()
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard), go11y.WithoutMigrations())
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	_, span := o.Tracer("test").Start(ctx, "exported")
+	span.End()
+	o.Close()
+}
+
+func TestGRPCExport(t *testing.T) {
+	certs := newTestCerts(t)
+
+	testCases := []struct {
+		name   string
+		tls    bool
+		scheme string
+	}{
+		{name: "insecure", scheme: "http"},
+		{name: "mutual TLS", tls: true, scheme: "https"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &grpcCollector{}
+
+			ec := go11y.ExporterConfig{
+				Protocol:    go11y.ProtocolGRPC,
+				Headers:     map[string]string{"x-api-key": "abc123"},
+				Timeout:     5 * time.Second,
+				Compression: go11y.CompressionNone,
+			}
+
+			var serverTLS *tls.Config
+			if tc.tls {
+				serverTLS = certs.serverTLS()
+				ec.TLS = go11y.TLSConfig{CAFile: certs.caFile, CertFile: certs.certFile, KeyFile: certs.keyFile}
+			}
+
+			addr := startGRPCCollector(t, c, serverTLS)
+
+			exportSpan(t, exporterConfigurator{
+				Configurator: go11y.CreateConfig(go11y.LevelInfo, tc.scheme+"://"+addr, "", "checkout", nil, nil),
+				ec:           ec,
+			})
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if len(c.requests) == 0 {
+				t.Fatalf("expected the spans to be exported")
+			}
+
+			if got := c.headers[0].Get("x-api-key"); len(got) != 1 || got[0] != "abc123" {
+				t.Errorf("expected the header to be sent, got %v", got)
+			}
+
+			name := c.requests[0].ResourceSpans[0].ScopeSpans[0].Spans[0].Name
+			if name != "exported" {
+				t.Errorf("expected the span to be named exported, got %s", name)
+			}
+		})
+	}
+}
+
+func TestHTTPExport(t *testing.T) {
+	certs := newTestCerts(t)
+
+	testCases := []struct {
+		name           string
+		protocol       string
+		tls            bool
+		expContentType string
+	}{
+		{name: "protobuf", protocol: go11y.ProtocolHTTPProtobuf, expContentType: "application/x-protobuf"},
+		{name: "protobuf with mutual TLS", protocol: go11y.ProtocolHTTPProtobuf, tls: true, expContentType: "application/x-protobuf"},
+		{name: "json", protocol: go11y.ProtocolHTTPJSON, expContentType: "application/json"},
+		{name: "json with mutual TLS", protocol: go11y.ProtocolHTTPJSON, tls: true, expContentType: "application/json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				handler  http.Handler
+				requests func() int
+				headers  func() http.Header
+			)
+
+			if tc.protocol == go11y.ProtocolHTTPJSON {
+				c := &jsonCollector{}
+				handler = c
+				requests = func() int { c.mu.Lock(); defer c.mu.Unlock(); return len(c.requests) }
+				headers = func() http.Header { c.mu.Lock(); defer c.mu.Unlock(); return c.headers[0] }
+			} else {
+				c := &collector{}
+				handler = c
+				requests = func() int { c.mu.Lock(); defer c.mu.Unlock(); return len(c.requests) }
+				headers = func() http.Header { c.mu.Lock(); defer c.mu.Unlock(); return c.headers[0] }
+			}
+
+			srv := httptest.NewUnstartedServer(handler)
+			ec := go11y.ExporterConfig{
+				Protocol:    tc.protocol,
+				Headers:     map[string]string{"x-api-key": "abc123"},
+				Compression: go11y.CompressionNone,
+			}
+
+			if tc.tls {
+				srv.TLS = certs.serverTLS()
+				srv.StartTLS()
+				ec.TLS = go11y.TLSConfig{CAFile: certs.caFile, CertFile: certs.certFile, KeyFile: certs.keyFile}
+			} else {
+				srv.Start()
+			}
+			defer srv.Close()
+
+			exportSpan(t, go11y.CreateConfig(go11y.LevelInfo, srv.URL+"/v1/traces", "", "checkout", nil, nil).WithExporterConfig(ec))
+
+			if requests() == 0 {
+				t.Fatalf("expected the spans to be exported")
+			}
+
+			if got := headers().Get("Content-Type"); got != tc.expContentType {
+				t.Errorf("expected the content type to be %s, got %s", tc.expContentType, got)
+			}
+
+			if got := headers().Get("X-Api-Key"); got != "abc123" {
+				t.Errorf("expected the header to be sent, got %q", got)
+			}
+		})
+	}
+}
+
+func TestHTTPJSONEncoding(t *testing.T) {
+	c := &jsonCollector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	exportSpan(t, go11y.CreateConfig(go11y.LevelInfo, srv.URL+"/v1/traces", "", "checkout", nil, nil).WithExporterConfig(go11y.ExporterConfig{Protocol: go11y.ProtocolHTTPJSON}))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.requests) == 0 {
+		t.Fatalf("expected the spans to be exported")
+	}
+
+	if got := c.headers[0].Get("Content-Encoding"); got != go11y.CompressionGzip {
+		t.Errorf("expected gzip compression by default, got %q", got)
+	}
+
+	data, _ := json.Marshal(c.requests[0])
+
+	req := struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID string `json:"traceId"`
+					SpanID  string `json:"spanId"`
+					Kind    int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}{}
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("could not decode the request: %v", err)
+	}
+
+	span := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+
+	if len(span.TraceID) != 32 || strings.Trim(span.TraceID, "0123456789abcdef") != "" {
+		t.Errorf("expected the trace ID to be hex encoded, got %s", span.TraceID)
+	}
+
+	if len(span.SpanID) != 16 || strings.Trim(span.SpanID, "0123456789abcdef") != "" {
+		t.Errorf("expected the span ID to be hex encoded, got %s", span.SpanID)
+	}
+
+	if span.Kind != 1 {
+		t.Errorf("expected the kind to be the number for internal spans, got %d", span.Kind)
+	}
+}
+
+func TestExporterTLSErrors(t *testing.T) {
+	certs := newTestCerts(t)
+
+	testCases := []struct {
+		name   string
+		url    string
+		tls    go11y.TLSConfig
+		expErr string
+	}{
+		{name: "certificate without key", url: "https://127.0.0.1:1", tls: go11y.TLSConfig{CertFile: certs.certFile}, expErr: "must be set together"},
+		{name: "missing CA file", url: "https://127.0.0.1:1", tls: go11y.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, expErr: "could not read the CA file"},
+		{name: "CA file without certificates", url: "https://127.0.0.1:1", tls: go11y.TLSConfig{CAFile: certs.keyFile}, expErr: "doesn't contain any PEM encoded certificates"},
+		{name: "TLS with an http URL", url: "http://127.0.0.1:1", tls: go11y.TLSConfig{CAFile: certs.caFile}, expErr: "is not https"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := go11y.CreateConfig(go11y.LevelInfo, tc.url, "", "checkout", nil, nil).WithExporterConfig(go11y.ExporterConfig{TLS: tc.tls})
+
+			_, _, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard), go11y.WithoutMigrations())
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				t.Errorf("expected an error containing %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigExporter(t *testing.T) {
+	certs := newTestCerts(t)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "2500")
+
+	cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", `
+tracing:
+  url: https://collector:4317
+  protocol: grpc
+  timeout: 5s
+  compression: none
+  tls:
+    ca_file: `+certs.caFile+`
+    cert_file: `+certs.certFile+`
+    key_file: `+certs.keyFile+`
+`))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	expected := go11y.ExporterConfig{
+		Protocol:    go11y.ProtocolGRPC,
+		Timeout:     2500 * time.Millisecond,
+		Compression: go11y.CompressionNone,
+		TLS:         go11y.TLSConfig{CAFile: certs.caFile, CertFile: certs.certFile, KeyFile: certs.keyFile},
+	}
+
+	if ec := cfg.ExporterConfig(); !reflect.DeepEqual(ec, expected) {
+		t.Errorf("expected the exporter config to be %+v, got %+v", expected, ec)
+	}
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", `
+tracing:
+  protocol: carrier-pigeon
+  timeout: soon
+  tls:
+    cert_file: client.pem
+`))
+	for _, e := range []string{"tracing.protocol: must be http/protobuf, http/json or grpc", "tracing.timeout", "tracing.tls.cert_file and tracing.tls.key_file"} {
+		if err == nil || !strings.Contains(err.Error(), e) {
+			t.Errorf("expected the error to contain %q, got %v", e, err)
+		}
+	}
+}
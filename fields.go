@@ -1,19 +1,31 @@
 package go11y
 
 const (
-	FieldRequestID       = "request_id"
-	FieldRequestMethod   = "request_method"
-	FieldRequestPath     = "request_path"
-	FieldRequestHeaders  = "request_headers"
-	FieldRequestURL      = "request_url"
-	FieldRequestBody     = "request_body"
-	FieldResponseHeaders = "response_headers"
-	FieldResponseBody    = "response_body"
-	FieldCallDuration    = "call_duration"
-	FieldStatusCode      = "status_code"
-	FieldSpanID          = "span_id"
-	FieldTraceID         = "trace_id"
-	FieldRemoteTraceID   = "remote_trace_id"
-	FieldRemoteSpanID    = "remote_span_id"
-	FieldEnvironment     = "environment"
+	FieldRequestID        = "request_id"
+	FieldRequestMethod    = "request_method"
+	FieldRequestPath      = "request_path"
+	FieldRequestHeaders   = "request_headers"
+	FieldRequestURL       = "request_url"
+	FieldRequestBody      = "request_body"
+	FieldResponseHeaders  = "response_headers"
+	FieldResponseBody     = "response_body"
+	FieldCallDuration     = "call_duration"
+	FieldStatusCode       = "status_code"
+	FieldSpanID           = "span_id"
+	FieldTraceID          = "trace_id"
+	FieldRemoteTraceID    = "remote_trace_id"
+	FieldRemoteSpanID     = "remote_span_id"
+	FieldEnvironment      = "environment"
+	FieldServiceVersion   = "service_version"
+	FieldServiceNamespace = "service_namespace"
+	FieldInstanceID       = "instance_id"
+	FieldContainerID      = "container_id"
+	FieldK8sNamespace     = "k8s_namespace"
+	FieldK8sPod           = "k8s_pod"
+	FieldK8sNode          = "k8s_node"
+	FieldSeverityText     = "severity_text"
+	FieldSeverityNumber   = "severity_number"
+	FieldTracingMode      = "tracing_mode"
+	FieldTracingURL       = "tracing_url"
+	FieldTracingFile      = "tracing_file"
 )
@@ -12,6 +12,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jsnfwlr/go11y/db"
@@ -20,6 +21,8 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
 	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
 	otelTrace "go.opentelemetry.io/otel/trace"
 )
@@ -29,14 +32,27 @@ type Fields map[string]any
 type Observer struct {
 	cfg           Configurator
 	output        io.Writer
+	handler       slog.Handler
 	level         slog.Level
 	logger        *slog.Logger
 	traceProvider *otelSDKTrace.TracerProvider
+	resource      *otelResource.Resource
+	tracingMode   string
 	tracer        otelTrace.Tracer
 	stableArgs    []any
+	processors    *pipeline
+	redactor      *Redactor
 	db            *ObserverDB
 	span          otelTrace.Span
 	spans         []otelTrace.Span
+	exit          func(code int)
+	closers       []io.Closer
+	live          *liveConfig
+	opts          []Option       // the options passed to New, which override the configuration when it is reloaded
+	settings      *settings      // the settings from the configuration and the options
+	source        *Configuration // the configuration that is reloaded, if it was loaded from a file or the environment
+	reloadMu      sync.Mutex
+	stopWatches   []func()
 }
 
 type ObserverDB struct {
@@ -51,13 +67,31 @@ var obsKeyInstance go11yContextKey = "jsnfwlr/go11y"
 
 var og *Observer
 
+// Initialise sets up the observer with the configuration, writing the logs as JSON to logOutput (stdout if nil).
+// If cfg is nil the configuration is loaded from the environment variables. It is equivalent to calling New with
+// WithConfig, WithOutput and WithStableArgs.
 func Initialise(ctx context.Context, cfg Configurator, logOutput io.Writer, initialArgs ...any) (ctxWithGo11y context.Context, observer *Observer, fault error) {
-	if logOutput == nil {
-		logOutput = os.Stdout
-	}
+	return New(ctx, WithConfig(cfg), WithOutput(logOutput), WithStableArgs(initialArgs...))
+}
+
+// InitialiseWithHandler sets up the observer in the same way as Initialise, but sends the log records to the provided
+// slog.Handler instead of a JSON handler writing to an io.Writer, such as the one returned by NewSyslogHandler.
+// The handler is responsible for its own level filtering and formatting.
+func InitialiseWithHandler(ctx context.Context, cfg Configurator, handler slog.Handler, initialArgs ...any) (ctxWithGo11y context.Context, observer *Observer, fault error) {
+	return New(ctx, WithConfig(cfg), WithHandler(handler), WithStableArgs(initialArgs...))
+}
 
+// New sets up the observer with the options, which override the configuration loaded from the environment variables
+// with LoadConfig (or the configuration passed with WithConfig)
+func New(ctx context.Context, opts ...Option) (ctxWithGo11y context.Context, observer *Observer, fault error) {
 	var err error
 
+	s := &settings{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	cfg := s.cfg
 	if cfg == nil {
 		cfg, err = LoadConfig()
 		if err != nil {
@@ -65,69 +99,241 @@ func Initialise(ctx context.Context, cfg Configurator, logOutput io.Writer, init
 		}
 	}
 
-	tp, err := tracerProvider(ctx, cfg)
+	// settings from a configuration file or an ExporterConfigurator come first, so the options passed to New override them
+	if pre := configOptions(cfg); len(pre) != 0 {
+		s = &settings{}
+		for _, opt := range append(pre, opts...) {
+			opt(s)
+		}
+	}
+
+	// the configuration is reloaded from where it was loaded, even if its level is overridden
+	source, _ := cfg.(*Configuration)
+	if source != nil && source.path == "" && !source.fromEnv {
+		source = nil
+	}
+
+	if s.level != nil {
+		cfg = levelConfig{Configurator: cfg, level: *s.level}
+	}
+
+	live := newLiveConfig(cfg, s)
+
+	handler, output, closers, err := logHandler(cfg, s, live)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create log handler: %w", err)
+	}
+
+	res := serviceResource(ctx, cfg, s)
+
+	if attrs := append(identityAttrs(cfg), resourceLogAttrs(res)...); len(attrs) != 0 {
+		handler = handler.WithAttrs(attrs)
+	}
+
+	tp, err := tracerProvider(ctx, cfg, s, res, live.sampler)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create tracer: %w", err)
 	}
 
-	opts := defaultOptions(cfg)
+	otel.SetTextMapPropagator(propagator(s))
+
+	rules := DefaultRedactionRules()
+	if s.redaction != nil {
+		rules = s.redaction
+	}
+
+	redactor, err := NewRedactor(rules...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create redactor: %w", err)
+	}
+
+	handler = &redactingHandler{next: handler, redactor: redactor}
 
 	og = &Observer{
 		cfg:           cfg,
-		output:        logOutput,
-		logger:        slog.New(slog.NewJSONHandler(logOutput, opts)),
+		output:        output,
+		handler:       handler,
+		logger:        slog.New(handler),
 		traceProvider: tp,
-		stableArgs:    initialArgs,
+		resource:      res,
+		tracingMode:   tracingMode(cfg, s),
+		stableArgs:    s.stableArgs,
+		processors:    newPipeline(s.processors),
+		redactor:      redactor,
+		closers:       closers,
+		live:          live,
+		opts:          opts,
+		settings:      s,
+		source:        source,
 	}
 
-	dbConnStr := cfg.DBConStr()
-	if dbConnStr != "" {
-		odb := &ObserverDB{}
+	installOTelDiagnostics(og)
 
-		odb.conn, err = pgx.Connect(ctx, dbConnStr)
-		if err != nil {
-			return ctx, nil, fmt.Errorf("could not connect to postgres: %w", err)
+	if err := og.connectDB(ctx, s.pool); err != nil {
+		return ctx, nil, err
+	}
+
+	if og.db != nil && !s.skipMigrations {
+		if err := og.migrateDB(ctx); err != nil {
+			return ctx, nil, err
 		}
+		og.Debug("Database migrated successfully", nil)
+	}
 
-		odb.pool, err = pgxpool.New(ctx, dbConnStr)
+	ctx = context.WithValue(ctx, obsKeyInstance, og)
+	if len(s.stableArgs) != 0 {
+		ctx, og = Extend(ctx, s.stableArgs...)
+	}
+
+	og.logTracingMode()
+
+	if source != nil {
+		for _, w := range source.warnings {
+			og.Warning("ignored an invalid configuration value", "reason", w)
+		}
+	}
+
+	slog.SetDefault(og.logger)
+
+	fmt.Println("Initialised observer with context")
+
+	return ctx, og, nil
+}
+
+// logHandler creates the handler for the logs, which writes them to the sinks (or the output) in the format, unless a
+// handler was provided, and applies the package levels. The sinks can be replaced when the configuration is reloaded.
+// It returns the output (if there is one) and the closers for the sinks that need closing when the observer is closed.
+func logHandler(cfg Configurator, s *settings, live *liveConfig) (handler slog.Handler, output io.Writer, closers []io.Closer, fault error) {
+	if s.handler != nil {
+		handler = s.handler
+		if len(s.packageLevels) != 0 {
+			handler = &packageLevelHandler{next: handler, levels: live.packages}
+		}
+
+		return handler, nil, nil, nil
+	}
+
+	handler, output, closers, err := sinksHandler(cfg, s, defaultOptions(live))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	live.handlers = newHandlerSwitch(handler)
+
+	return &packageLevelHandler{next: &switchHandler{sw: live.handlers}, levels: live.packages}, output, closers, nil
+}
+
+// sinksHandler creates a handler that writes the logs to the sinks (or the output if there aren't any) in the format
+func sinksHandler(cfg Configurator, s *settings, ho *slog.HandlerOptions) (handler slog.Handler, output io.Writer, closers []io.Closer, fault error) {
+	if len(s.sinks) == 0 {
+		output = s.output
+		if output == nil {
+			output = os.Stdout
+		}
+
+		return formatHandler(s.format, output, ho), output, nil, nil
+	}
+
+	handlers := multiHandler{}
+
+	for _, sink := range s.sinks {
+		h, c, err := sinkHandler(sink, s.format, cfg.ServiceName(), ho)
 		if err != nil {
-			return ctx, nil, fmt.Errorf("could not create connection pool: %w", err)
+			for _, c := range closers {
+				_ = c.Close()
+			}
+
+			return nil, nil, nil, err
+		}
+
+		handlers = append(handlers, h)
+		if c != nil {
+			closers = append(closers, c)
+		}
+	}
+
+	if len(handlers) == 1 {
+		return handlers[0], nil, closers, nil
+	}
+
+	return handlers, nil, closers, nil
+}
+
+// connectDB sets up the database used to store the roundtrip requests, using the pool if there is one, or connecting
+// with the connection string from the configuration if there is one of those
+func (o *Observer) connectDB(ctx context.Context, pool *pgxpool.Pool) (fault error) {
+	var err error
+
+	if pool != nil {
+		o.db = &ObserverDB{
+			pool:    pool,
+			queries: db.New(pool),
 		}
 
-		odb.queries = db.New(odb.conn)
+		return nil
+	}
+
+	dbConnStr := o.cfg.DBConStr()
+	if dbConnStr == "" {
+		return nil
+	}
+
+	odb := &ObserverDB{}
 
-		og.db = odb
+	odb.conn, err = pgx.Connect(ctx, dbConnStr)
+	if err != nil {
+		return fmt.Errorf("could not connect to postgres: %w", err)
+	}
+
+	odb.pool, err = pgxpool.New(ctx, dbConnStr)
+	if err != nil {
+		return fmt.Errorf("could not create connection pool: %w", err)
+	}
+
+	odb.queries = db.New(odb.conn)
+
+	o.db = odb
+
+	return nil
+}
+
+// migrateDB runs the migrations for the database used to store the roundtrip requests
+func (o *Observer) migrateDB(ctx context.Context) (fault error) {
+	col, err := migrations.New()
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var dbMig db.DBMigrator
 
-		col, err := migrations.New()
+	if o.db.conn == nil {
+		conn, err := o.db.pool.Acquire(ctx)
 		if err != nil {
-			return ctx, nil, fmt.Errorf("failed to read migrations: %w", err)
+			return fmt.Errorf("could not acquire a connection for the migrations: %w", err)
 		}
+		defer conn.Release()
 
-		dbMig, err := db.NewMigrator(ctx, og, cfg, col)
+		dbMig, err = db.NewMigratorWithConn(ctx, o, o.cfg, conn.Conn(), col)
 		if err != nil {
-			return ctx, nil, fmt.Errorf("could not create migrator: %w", err)
+			return fmt.Errorf("could not create migrator: %w", err)
 		}
-		err = dbMig.Migrate()
+	} else {
+		dbMig, err = db.NewMigrator(ctx, o, o.cfg, col)
 		if err != nil {
-			return ctx, nil, fmt.Errorf("could not migrate database: %w", err)
+			return fmt.Errorf("could not create migrator: %w", err)
 		}
-		og.Debug("Database migrated successfully", nil)
 	}
 
-	ctx = context.WithValue(ctx, obsKeyInstance, og)
-	if len(initialArgs) != 0 {
-		ctx, og = Extend(ctx, initialArgs...)
+	err = dbMig.Migrate()
+	if err != nil {
+		return fmt.Errorf("could not migrate database: %w", err)
 	}
 
-	slog.SetDefault(og.logger)
-
-	fmt.Println("Initialised observer with context")
-
-	return ctx, og, nil
+	return nil
 }
 
 func Reset(ctxWithGo11y context.Context) (ctxWithResetObservability context.Context) {
-	og.logger = slog.New(slog.NewJSONHandler(og.output, defaultOptions(og.cfg)))
+	og.logger = slog.New(og.handler)
 	og.Debug("Observer reset", nil)
 	og.stableArgs = []any{}
 
@@ -186,8 +392,18 @@ func Expand(ctx context.Context, tracer otelTrace.Tracer, spanName string, spanK
 	return context.WithValue(ctx, obsKeyInstance, o), o
 }
 
-// Close ends all active spans and shuts down the trace provider to ensure all traces are flushed.
+// Close ends all active spans, stops watching the configuration and shuts down the trace provider to ensure all traces
+// are flushed.
 func (o *Observer) Close() {
+	o.reloadMu.Lock()
+	stopWatches := o.stopWatches
+	o.stopWatches = nil
+	o.reloadMu.Unlock()
+
+	for _, stop := range stopWatches {
+		stop()
+	}
+
 	if o.span != nil {
 		o.span.End()
 
@@ -199,69 +415,67 @@ func (o *Observer) Close() {
 	if err := o.traceProvider.Shutdown(context.Background()); err != nil {
 		o.Fatal("could not shut down tracer", err)
 	}
+
+	o.reloadMu.Lock()
+	for _, c := range o.closers {
+		_ = c.Close()
+	}
+	o.closers = nil
+	o.reloadMu.Unlock()
 }
 
-// defaultReplacer creates a function to replace or modify log attributes
-func defaultReplacer(trimModules, trimPaths []string) func(groups []string, a slog.Attr) slog.Attr {
-	return func(groups []string, a slog.Attr) slog.Attr {
-		if os.Getenv("ENV") == "test" && a.Key == slog.TimeKey {
-			return slog.Attr{} // remove time key in test to make it easier to compare
-		}
+// replaceAttr replaces or modifies the log attributes, trimming the modules and paths from the source
+func replaceAttr(trimModules, trimPaths []string, groups []string, a slog.Attr) slog.Attr {
+	if os.Getenv("ENV") == "test" && a.Key == slog.TimeKey {
+		return slog.Attr{} // remove time key in test to make it easier to compare
+	}
 
-		switch a.Key {
-		case slog.SourceKey:
-			source, ok := a.Value.Any().(*slog.Source)
-			if !ok {
-				return a
-			}
+	switch a.Key {
+	case slog.SourceKey:
+		source, ok := a.Value.Any().(*slog.Source)
+		if !ok {
+			return a
+		}
 
-			for _, path := range trimPaths {
-				if idx := strings.Index(source.File, path); idx != -1 {
-					source.File = source.File[idx+len(path):]
-				}
+		for _, path := range trimPaths {
+			if idx := strings.Index(source.File, path); idx != -1 {
+				source.File = source.File[idx+len(path):]
 			}
+		}
 
-			for _, module := range trimModules {
-				if idx := strings.Index(source.Function, module); idx != -1 {
-					source.Function = source.Function[idx+len(module):]
-				}
+		for _, module := range trimModules {
+			if idx := strings.Index(source.Function, module); idx != -1 {
+				source.Function = source.Function[idx+len(module):]
 			}
+		}
 
-			return slog.Any(a.Key, source)
-		case slog.LevelKey:
-			var level slog.Level
-
-			if lvl, ok := a.Value.Any().(slog.Level); ok {
-				level = lvl
-			} else {
-				level = ParseLevel(fmt.Sprintf("%v", a.Value.Any()))
-			}
+		return slog.Any(a.Key, source)
+	case slog.LevelKey:
+		level, ok := a.Value.Any().(slog.Level)
+		if !ok {
+			var err error
 
-			switch level {
-			case LevelDebug:
-				a.Value = slog.StringValue("DEBUG")
-			case LevelInfo:
-				a.Value = slog.StringValue("INFO")
-			case LevelNotice:
-				a.Value = slog.StringValue("NOTICE")
-			case LevelWarning:
-				a.Value = slog.StringValue("WARN")
-			case LevelError:
-				a.Value = slog.StringValue("ERR")
-			case LevelFatal:
-				a.Value = slog.StringValue("FATAL")
-			default:
-				a.Value = slog.StringValue("DEBUG")
+			level, err = ParseLevelStrict(fmt.Sprintf("%v", a.Value.Any()))
+			if err != nil {
+				return a // leave levels we don't know about as they are, rather than guessing
 			}
 		}
 
-		return a
+		a.Value = slog.StringValue(LevelName(level))
 	}
+
+	return a
 }
 
-func (o *Observer) log(ctx context.Context, skipCallers int, level slog.Level, msg string, args ...any) (logged bool) {
+// log builds a record for the message and args, runs it through the processors and passes it to the handler. It returns
+// the processed record, and false if the level is disabled or a processor dropped the record.
+func (o *Observer) log(ctx context.Context, skipCallers int, level slog.Level, msg string, args ...any) (record slog.Record, logged bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	if o.logger == nil || !o.logger.Enabled(ctx, level) {
-		return false
+		return slog.Record{}, false
 	}
 	var pc uintptr
 	var pcs [1]uintptr
@@ -275,12 +489,15 @@ func (o *Observer) log(ctx context.Context, skipCallers int, level slog.Level, m
 		r.Add(args...)
 	}
 
-	if ctx == nil {
-		ctx = context.Background()
+	for _, p := range o.processors.all() {
+		if !p.Process(ctx, &r) {
+			return r, false
+		}
 	}
+
 	_ = o.logger.Handler().Handle(ctx, r)
 
-	return true
+	return r, true
 }
 
 func (o *Observer) store(ctx context.Context, url, method string, statusCode int32, duration time.Duration, requestBody, responseBody []byte, requestHeaders, responseHeaders http.Header) (fault error) {
@@ -289,13 +506,17 @@ func (o *Observer) store(ctx context.Context, url, method string, statusCode int
 		return nil
 	}
 
-	reqHead, err := json.Marshal(requestHeaders)
+	url = o.redactor.RedactString(FieldRequestURL, url)
+	requestBody = []byte(o.redactor.RedactString(FieldRequestBody, string(requestBody)))
+	responseBody = []byte(o.redactor.RedactString(FieldResponseBody, string(responseBody)))
+
+	reqHead, err := json.Marshal(o.redactor.RedactHeaders(requestHeaders))
 	if err != nil {
 		o.Error("Failed to marshal request headers to JSON", err, SeverityMedium)
 		return err
 	}
 
-	respHead, err := json.Marshal(responseHeaders)
+	respHead, err := json.Marshal(o.redactor.RedactHeaders(responseHeaders))
 	if err != nil {
 		o.Error("Failed to marshal response headers to JSON", err, SeverityMedium)
 		return err
@@ -0,0 +1,300 @@
+// Package go11ytest provides an in-memory go11y Observer for tests, which captures log records and spans so they can be
+// checked with assertion helpers instead of parsing the JSON output line by line.
+package go11ytest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/internal/testhooks"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Record is a log record captured by the Observer, with the stable args and the ephemeral args merged into Attrs.
+// The keys of grouped attributes are joined with dots.
+type Record struct {
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// String formats the record for failure messages
+func (r Record) String() string {
+	return fmt.Sprintf("%s %q %v", go11y.LevelName(r.Level), r.Message, r.Attrs)
+}
+
+// Observer is a go11y.Observer that captures everything it logs, at every level, and records its spans (once they have
+// ended) in an in-memory exporter. Fatal logs the message without exiting. It replaces the global observer and trace provider, so tests using it must not run
+// in parallel.
+type Observer struct {
+	*go11y.Observer
+	t        testing.TB
+	ctx      context.Context
+	recorder *recorder
+	exporter *tracetest.InMemoryExporter
+}
+
+// NewObserver initialises an Observer with the initial args as its stable args, and closes it when the test finishes
+func NewObserver(t testing.TB, initialArgs ...any) *Observer {
+	t.Helper()
+
+	rec := &recorder{}
+	cfg := go11y.CreateConfig(go11y.LevelDevelop, "", "", "go11ytest", nil, nil)
+
+	// create the trace provider first, so its warning about using a syncer is not recorded
+	exporter := tracetest.NewInMemoryExporter()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+
+	ctx, o, err := go11y.InitialiseWithHandler(context.Background(), cfg, &handler{recorder: rec}, initialArgs...)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	if err := o.UseTracerProvider(ctx, tp); err != nil {
+		t.Fatalf("failed to replace the trace provider: %v", err)
+	}
+
+	testhooks.SetExitFunc(o, rec.exit)
+
+	// discard what the observer logged about itself while it was set up, so only the test's records are captured
+	rec.reset()
+
+	t.Cleanup(func() {
+		o.Close()
+	})
+
+	return &Observer{
+		Observer: o,
+		t:        t,
+		ctx:      ctx,
+		recorder: rec,
+		exporter: exporter,
+	}
+}
+
+// Context returns the context holding the observer
+func (o *Observer) Context() context.Context {
+	return o.ctx
+}
+
+// Records returns a copy of the log records captured so far
+func (o *Observer) Records() []Record {
+	return o.recorder.all()
+}
+
+// Spans returns the spans that have ended so far
+func (o *Observer) Spans() tracetest.SpanStubs {
+	return o.exporter.GetSpans()
+}
+
+// Exited reports whether Fatal has been called, which logs the message but does not exit the test
+func (o *Observer) Exited() bool {
+	o.recorder.mu.Lock()
+	defer o.recorder.mu.Unlock()
+
+	return o.recorder.exited
+}
+
+// Reset discards the records and spans captured so far
+func (o *Observer) Reset() {
+	o.recorder.reset()
+	o.exporter.Reset()
+}
+
+// RequireLogged fails the test unless a record was logged with the level and message, and with all the attrs (as key/value
+// pairs). Values are compared by their formatted value, so an int arg matches an int64 attribute.
+func (o *Observer) RequireLogged(level slog.Level, msg string, attrs ...any) {
+	o.t.Helper()
+
+	records := o.Records()
+	for _, r := range records {
+		if r.Level == level && r.Message == msg && hasAttrs(r.Attrs, attrs) {
+			return
+		}
+	}
+
+	o.t.Fatalf("expected a %s record %q with attributes %v, got:\n%s", go11y.LevelName(level), msg, attrs, formatRecords(records))
+}
+
+// RequireSpan fails the test unless a span with the name and all the attrs (as key/value pairs) has ended
+func (o *Observer) RequireSpan(name string, attrs ...any) {
+	o.t.Helper()
+
+	spans := o.Spans()
+	names := make([]string, 0, len(spans))
+
+	for _, s := range spans {
+		names = append(names, s.Name)
+
+		if s.Name != name {
+			continue
+		}
+
+		sa := map[string]any{}
+		for _, a := range s.Attributes {
+			sa[string(a.Key)] = a.Value.Emit()
+		}
+
+		if hasAttrs(sa, attrs) {
+			return
+		}
+	}
+
+	o.t.Fatalf("expected a span %q with attributes %v, got spans %v", name, attrs, names)
+}
+
+// NoErrorsLogged fails the test if any record was logged at the error level or above
+func (o *Observer) NoErrorsLogged() {
+	o.t.Helper()
+
+	errs := []Record{}
+	for _, r := range o.Records() {
+		if r.Level >= go11y.LevelError {
+			errs = append(errs, r)
+		}
+	}
+
+	if len(errs) != 0 {
+		o.t.Fatalf("expected no errors to be logged, got:\n%s", formatRecords(errs))
+	}
+}
+
+func hasAttrs(actual map[string]any, expected []any) bool {
+	for i := 0; i+1 < len(expected); i += 2 {
+		v, ok := actual[fmt.Sprintf("%v", expected[i])]
+		if !ok || fmt.Sprintf("%v", v) != fmt.Sprintf("%v", expected[i+1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatRecords(records []Record) string {
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = "  " + r.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	records []Record
+	exited  bool
+}
+
+func (r *recorder) exit(int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exited = true
+}
+
+func (r *recorder) add(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, rec)
+}
+
+func (r *recorder) all() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Record{}, r.records...)
+}
+
+func (r *recorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+	r.exited = false
+}
+
+// handler captures every record in the recorder, along with the attributes added by WithAttrs
+type handler struct {
+	recorder *recorder
+	attrs    []slog.Attr
+	groups   []string
+}
+
+func (h *handler) Enabled(_ context.Context, _ slog.Level) bool {
+	return true
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), group(h.groups, attrs)...)
+
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+
+	return &h2
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	rec := Record{
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   map[string]any{},
+	}
+
+	attrs := append([]slog.Attr{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, group(h.groups, []slog.Attr{a})...)
+		return true
+	})
+
+	for _, a := range attrs {
+		flatten("", a, rec.Attrs)
+	}
+
+	h.recorder.add(rec)
+
+	return nil
+}
+
+// group nests the attributes in the groups, so they are flattened with the group names as a prefix
+func group(groups []string, attrs []slog.Attr) []slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+
+	return attrs
+}
+
+func flatten(prefix string, a slog.Attr, into map[string]any) {
+	a.Value = a.Value.Resolve()
+
+	key := a.Key
+	if prefix != "" {
+		key = prefix + "." + a.Key
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			flatten(key, ga, into)
+		}
+		return
+	}
+
+	into[key] = a.Value.Any()
+}
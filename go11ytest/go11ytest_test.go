@@ -0,0 +1,82 @@
+package go11ytest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/go11ytest"
+)
+
+// fakeT records failures instead of stopping the test, so the assertion helpers can be checked
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestObserver(t *testing.T) {
+	obs := go11ytest.NewObserver(t, "service", "checkout")
+
+	ctx, o := go11y.Extend(obs.Context(), "order_id", 42)
+	o.Info("order placed", "items", 3)
+
+	_, o = go11y.Span(ctx, obs.Tracer("go11ytest"), "place order", go11y.SpanKindInternal)
+	o.Notice("payment taken", "amount", 9.99)
+	o.End()
+
+	obs.RequireLogged(go11y.LevelInfo, "order placed", "service", "checkout", "order_id", 42, "items", 3)
+	obs.RequireLogged(go11y.LevelNotice, "payment taken", "amount", 9.99)
+	obs.RequireSpan("place order", "order_id", 42, "amount", 9.99)
+	obs.NoErrorsLogged()
+
+	if len(obs.Records()) != 2 {
+		t.Errorf("expected 2 records, got %v", obs.Records())
+	}
+
+	obs.Reset()
+
+	if len(obs.Records()) != 0 || len(obs.Spans()) != 0 {
+		t.Errorf("expected reset to discard the records and spans")
+	}
+}
+
+func TestObserverFailures(t *testing.T) {
+	ft := &fakeT{TB: t}
+	obs := go11ytest.NewObserver(ft)
+
+	obs.Error("it broke", errors.New("boom"), go11y.SeverityLow)
+
+	obs.RequireLogged(go11y.LevelInfo, "it broke")
+	obs.RequireLogged(go11y.LevelError, "it broke", "severity", go11y.SeverityHigh)
+	obs.RequireSpan("missing")
+	obs.NoErrorsLogged()
+
+	if len(ft.failures) != 4 {
+		t.Errorf("expected 4 failures, got %d: %v", len(ft.failures), ft.failures)
+	}
+
+	obs.RequireLogged(go11y.LevelError, "it broke", "error", "boom", "severity", go11y.SeverityLow)
+
+	if len(ft.failures) != 4 {
+		t.Errorf("expected the matching record to pass, got %v", ft.failures[4:])
+	}
+}
+
+func TestObserverFatal(t *testing.T) {
+	obs := go11ytest.NewObserver(t)
+
+	obs.Fatal("cannot continue", errors.New("boom"))
+
+	if !obs.Exited() {
+		t.Error("expected Fatal to be recorded as an exit")
+	}
+
+	obs.RequireLogged(go11y.LevelFatal, "cannot continue", "severity", go11y.SeverityHighest)
+}
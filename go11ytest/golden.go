@@ -0,0 +1,130 @@
+package go11ytest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/internal/testhooks"
+)
+
+// UpdateGoldenEnv is the environment variable that makes Golden rewrite the golden files when it is set to true. It is
+// an environment variable rather than a flag, so it can't clash with the flags of the tests that use the package.
+const UpdateGoldenEnv = "GO11Y_UPDATE_GOLDEN"
+
+type normalizer struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// the order matters: UUIDs and trace IDs are replaced before the shorter span IDs could match part of them
+var normalizers = []normalizer{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "<TIME>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<UUID>"},
+	{regexp.MustCompile(`\b[0-9a-f]{32}\b`), "<TRACE_ID>"},
+	{regexp.MustCompile(`\b[0-9a-f]{16}\b`), "<SPAN_ID>"},
+	{regexp.MustCompile(`("[A-Za-z_.]*duration[A-Za-z_.]*"):\s*-?[0-9.e+]+`), `$1:"<DURATION>"`},
+	{regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h)(\d+(\.\d+)?(ns|µs|us|ms|s|m|h))*\b`), "<DURATION>"},
+	{regexp.MustCompile(`"line":\s*\d+`), `"line":"<LINE>"`},
+}
+
+// Normalize replaces the values in log output that change from run to run (timestamps, UUIDs, trace and span IDs, durations
+// and source line numbers) with placeholders, so the output can be compared with a golden file.
+func Normalize(output []byte) []byte {
+	for _, n := range normalizers {
+		output = n.pattern.ReplaceAll(output, []byte(n.replacement))
+	}
+
+	return output
+}
+
+// Golden normalizes the output and compares it with testdata/<name>.golden, failing the test if they differ. When the test
+// is run with GO11Y_UPDATE_GOLDEN=true, the golden file is rewritten with the normalized output instead.
+func Golden(t testing.TB, name string, output []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	actual := Normalize(output)
+
+	if update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv)); update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create the testdata directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", path, err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s (run the test with %s=true to create it): %v", path, UpdateGoldenEnv, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("output does not match golden file %s (run the test with %s=true to accept it):\n%s", path, UpdateGoldenEnv, diffLines(expected, actual))
+	}
+}
+
+// NewGoldenObserver initialises an Observer that writes JSON logs, at every level, to the returned buffer for use with Golden.
+// Fatal logs the message without exiting, and the observer is closed when the test finishes. Where the tests run isn't
+// detected, so the output doesn't change between machines and containers.
+func NewGoldenObserver(t testing.TB, initialArgs ...any) (ctx context.Context, observer *go11y.Observer, output *bytes.Buffer) {
+	t.Helper()
+
+	t.Setenv("ENV", "test")
+
+	wd, _ := os.Getwd()
+
+	buf := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelDevelop, "", "", "go11ytest", nil, []string{wd})
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(buf), go11y.WithStableArgs(initialArgs...), go11y.WithResourceDetectors())
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	testhooks.SetExitFunc(o, func(int) {})
+
+	// discard what the observer logged about itself while it was set up, so the golden file only holds the test's output
+	buf.Reset()
+
+	t.Cleanup(func() {
+		o.Close()
+	})
+
+	return ctx, o, buf
+}
+
+// diffLines describes the lines that differ between the expected and actual output
+func diffLines(expected, actual []byte) string {
+	el := strings.Split(string(expected), "\n")
+	al := strings.Split(string(actual), "\n")
+
+	b := &strings.Builder{}
+
+	for i := 0; i < max(len(el), len(al)); i++ {
+		var e, a string
+		if i < len(el) {
+			e = el[i]
+		}
+		if i < len(al) {
+			a = al[i]
+		}
+
+		if e != a {
+			fmt.Fprintf(b, "line %d:\n- %s\n+ %s\n", i+1, e, a)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package go11ytest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/go11ytest"
+)
+
+func TestNormalize(t *testing.T) {
+	input := `{"time":"2025-08-04T10:14:19.780509481+08:00","source":{"line":81},"request_id":"` + uuid.New().String() + `",` +
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","call_duration":1234567,"took":"1.5ms"}`
+
+	expected := `{"time":"<TIME>","source":{"line":"<LINE>"},"request_id":"<UUID>",` +
+		`"trace_id":"<TRACE_ID>","span_id":"<SPAN_ID>","call_duration":"<DURATION>","took":"<DURATION>"}`
+
+	if got := string(go11ytest.Normalize([]byte(input))); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	ctx, o, buf := go11ytest.NewGoldenObserver(t, "service", "golden")
+
+	_, o = go11y.Extend(ctx, go11y.FieldRequestID, uuid.New().String())
+	o.Info("request handled", go11y.FieldCallDuration, 42*time.Millisecond, "took", (3 * time.Second).String())
+	o.Warning("slow request")
+
+	go11ytest.Golden(t, "golden", buf.Bytes())
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(go11ytest.UpdateGoldenEnv, "true")
+
+	go11ytest.Golden(t, "updated", []byte(`{"msg":"updated"}`))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "updated.golden"))
+	if err != nil || string(expected) != `{"msg":"updated"}` {
+		t.Errorf("expected the golden file to be written, got %q (%v)", expected, err)
+	}
+}
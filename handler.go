@@ -0,0 +1,98 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// Handler returns a slog.Handler that sends records through the observer's processors and then to its handler, with the
+// observer's stable args and redaction. It always uses the observer's current logger, so args added with Extend after
+// the handler was created are included. The trace and span IDs of the span in the context passed to Handle are added to
+// the record, so the logs of bridged loggers are correlated with the request they were logged in. This is intended for
+// bridging other loggers into the observer.
+func (o *Observer) Handler() slog.Handler {
+	return &observerHandler{observer: o}
+}
+
+type observerHandler struct {
+	observer *Observer
+	attrs    []slog.Attr
+	groups   []string
+}
+
+func (h *observerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.observer.logger != nil && h.observer.logger.Enabled(ctx, level)
+}
+
+func (h *observerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), group(h.groups, attrs)...)
+
+	return &h2
+}
+
+func (h *observerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+
+	return &h2
+}
+
+func (h *observerHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if len(h.attrs) != 0 || len(h.groups) != 0 {
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		nr.AddAttrs(h.attrs...)
+
+		r.Attrs(func(a slog.Attr) bool {
+			nr.AddAttrs(group(h.groups, []slog.Attr{a})...)
+			return true
+		})
+
+		r = nr
+	}
+
+	if sc := otelTrace.SpanContextFromContext(ctx); sc.IsValid() && !hasAttr(r, FieldTraceID) {
+		r.AddAttrs(slog.String(FieldTraceID, sc.TraceID().String()), slog.String(FieldSpanID, sc.SpanID().String()))
+	}
+
+	for _, p := range h.observer.processors.all() {
+		if !p.Process(ctx, &r) {
+			return nil
+		}
+	}
+
+	return h.observer.logger.Handler().Handle(ctx, r)
+}
+
+// hasAttr returns true if the record has an attribute with the key
+func hasAttr(r slog.Record, key string) (found bool) {
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == key
+		return !found
+	})
+
+	return found
+}
+
+// group nests the attributes in the groups, from the outermost to the innermost
+func group(groups []string, attrs []slog.Attr) []slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+
+	return attrs
+}
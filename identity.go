@@ -0,0 +1,119 @@
+package go11y
+
+import (
+	"log/slog"
+	"runtime/debug"
+)
+
+// Identity describes the deployment of the service, which is added to the trace resource and to every log record
+type Identity struct {
+	Environment string // such as production or staging
+	Version     string // the version of the service, from the build info if it is empty
+	Namespace   string // the group of services the service belongs to
+	InstanceID  string // the unique ID of this instance of the service, such as the pod name
+}
+
+// IdentityConfigurator is implemented by Configurators that configure the identity of the service. It is separate from
+// the Configurator interface so existing implementations don't have to change.
+type IdentityConfigurator interface {
+	Environment() string
+	ServiceVersion() string
+	ServiceNamespace() string
+	InstanceID() string
+}
+
+// WithIdentity sets the environment, version, namespace and instance ID of the service. The version from the build
+// info is kept if the identity's version is empty.
+func (c *Configuration) WithIdentity(id Identity) *Configuration {
+	c.environment = id.Environment
+	c.serviceNamespace = id.Namespace
+	c.instanceID = id.InstanceID
+
+	if id.Version != "" {
+		c.serviceVersion = id.Version
+	}
+
+	if c.sources == nil {
+		c.sources = map[string]ConfigValue{}
+	}
+
+	for k, v := range map[string]string{"environment": id.Environment, "service.version": id.Version, "service.namespace": id.Namespace, "service.instance_id": id.InstanceID} {
+		if v != "" {
+			c.sources[k] = ConfigValue{Source: SourceCode}
+		}
+	}
+
+	return c
+}
+
+// buildVersion returns the version of the main module from the build info or, if it doesn't have one, the VCS revision
+// it was built from (with -dirty added if there were uncommitted changes)
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	revision, modified := "", false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+
+	return revision
+}
+
+// configIdentity returns the identity of the service from the configuration. A Configurator that doesn't implement
+// IdentityConfigurator only has the version from the build info.
+func configIdentity(cfg Configurator) Identity {
+	if lc, ok := cfg.(levelConfig); ok {
+		cfg = lc.Configurator
+	}
+
+	ic, ok := cfg.(IdentityConfigurator)
+	if !ok {
+		return Identity{Version: buildVersion()}
+	}
+
+	return Identity{
+		Environment: ic.Environment(),
+		Version:     ic.ServiceVersion(),
+		Namespace:   ic.ServiceNamespace(),
+		InstanceID:  ic.InstanceID(),
+	}
+}
+
+// identityAttrs returns the log fields for the parts of the identity that are set
+func identityAttrs(cfg Configurator) []slog.Attr {
+	attrs := []slog.Attr{}
+	id := configIdentity(cfg)
+
+	for _, f := range []struct{ key, value string }{
+		{FieldEnvironment, id.Environment},
+		{FieldServiceVersion, id.Version},
+		{FieldServiceNamespace, id.Namespace},
+		{FieldInstanceID, id.InstanceID},
+	} {
+		if f.value != "" {
+			attrs = append(attrs, slog.String(f.key, f.value))
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,159 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestIdentity(t *testing.T) {
+	testCases := []struct {
+		name   string
+		env    map[string]string
+		config func(t *testing.T) go11y.Configurator
+	}{
+		{
+			name: "environment variables",
+			env: map[string]string{
+				"OTEL_SERVICE_NAME":      "checkout",
+				"DEPLOYMENT_ENVIRONMENT": "staging",
+				"SERVICE_VERSION":        "1.2.3",
+				"SERVICE_NAMESPACE":      "shop",
+				"SERVICE_INSTANCE_ID":    "checkout-7d9f",
+			},
+		},
+		{
+			name: "resource attributes",
+			env: map[string]string{
+				"OTEL_SERVICE_NAME":        "checkout",
+				"OTEL_RESOURCE_ATTRIBUTES": "deployment.environment=staging,service.version=1.2.3,service.namespace=shop,service.instance.id=checkout-7d9f,team=payments",
+			},
+		},
+		{
+			name: "created",
+			config: func(t *testing.T) go11y.Configurator {
+				return go11y.CreateConfig(go11y.LevelInfo, "", "", "checkout", nil, nil).WithIdentity(go11y.Identity{
+					Environment: "staging",
+					Version:     "1.2.3",
+					Namespace:   "shop",
+					InstanceID:  "checkout-7d9f",
+				})
+			},
+		},
+		{
+			name: "identity configurator",
+			config: func(t *testing.T) go11y.Configurator {
+				return identityConfigurator{go11y.CreateConfig(go11y.LevelInfo, "", "", "checkout", nil, nil)}
+			},
+		},
+		{
+			name: "config file",
+			env: map[string]string{
+				"SERVICE_INSTANCE_ID": "checkout-7d9f",
+			},
+			config: func(t *testing.T) go11y.Configurator {
+				cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", `
+environment: staging
+service:
+  name: checkout
+  version: 1.2.3
+  namespace: shop
+  instance_id: from-the-file
+`))
+				if err != nil {
+					t.Fatalf("failed to load config file: %v", err)
+				}
+
+				return cfg
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg go11y.Configurator
+			if tc.config != nil {
+				cfg = tc.config(t)
+			} else {
+				loaded, err := go11y.LoadConfig()
+				if err != nil {
+					t.Fatalf("failed to load config: %v", err)
+				}
+				cfg = loaded
+			}
+
+			buf := &bytes.Buffer{}
+			exporter := tracetest.NewInMemoryExporter()
+
+			ctx, o, err := go11y.New(context.Background(),
+				go11y.WithConfig(cfg),
+				go11y.WithOutput(buf),
+				go11y.WithTraceExporter(keepSpans{exporter}),
+			)
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+
+			_, o = go11y.Span(ctx, o.Tracer("test"), "identity", go11y.SpanKindInternal)
+			o.Info("identified")
+			o.End()
+			o.Close()
+
+			line := map[string]any{}
+			if err := json.Unmarshal([]byte(strings.Join(withoutTracingMode(buf.String()), "\n")), &line); err != nil {
+				t.Fatalf("could not unmarshal log line: %v", err)
+			}
+
+			expFields := map[string]string{
+				go11y.FieldEnvironment:      "staging",
+				go11y.FieldServiceVersion:   "1.2.3",
+				go11y.FieldServiceNamespace: "shop",
+				go11y.FieldInstanceID:       "checkout-7d9f",
+			}
+			for k, v := range expFields {
+				if line[k] != v {
+					t.Errorf("expected the %s log field to be %s, got %v", k, v, line[k])
+				}
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			expAttrs := map[string]string{
+				"service.name":           "checkout",
+				"deployment.environment": "staging",
+				"service.version":        "1.2.3",
+				"service.namespace":      "shop",
+				"service.instance.id":    "checkout-7d9f",
+			}
+			for k, v := range expAttrs {
+				got, ok := spans[0].Resource.Set().Value(attribute.Key(k))
+				if !ok || got.AsString() != v {
+					t.Errorf("expected the %s resource attribute to be %s, got %v", k, v, got.AsString())
+				}
+			}
+		})
+	}
+}
+
+// identityConfigurator is a Configurator that also configures the identity of the service
+type identityConfigurator struct {
+	go11y.Configurator
+}
+
+func (identityConfigurator) Environment() string      { return "staging" }
+func (identityConfigurator) ServiceVersion() string   { return "1.2.3" }
+func (identityConfigurator) ServiceNamespace() string { return "shop" }
+func (identityConfigurator) InstanceID() string       { return "checkout-7d9f" }
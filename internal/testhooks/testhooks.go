@@ -0,0 +1,7 @@
+// Package testhooks lets the go11ytest package (and go11y's own tests) change how an observer behaves in tests, without
+// adding to the go11y API
+package testhooks
+
+// SetExitFunc replaces the function the observer's Fatal method uses to exit the application (os.Exit by default), such
+// as with one that records the exit. It is set by the go11y package, and panics if the observer isn't a *go11y.Observer.
+var SetExitFunc func(observer any, exit func(code int))
@@ -0,0 +1,363 @@
+package go11y
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jsnfwlr/go11y/db"
+)
+
+// Where the values of the effective configuration came from
+const (
+	SourceDefault = "default" // the value go11y uses if nothing else sets it
+	SourceEnv     = "env"     // an environment variable
+	SourceFile    = "file"    // a configuration file
+	SourceCode    = "code"    // CreateConfig, a Configurator implementation or an option passed to New
+)
+
+// ConfigValue is a value of the effective configuration, and where it came from
+type ConfigValue struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`         // one of SourceDefault, SourceEnv, SourceFile or SourceCode
+	From   string `json:"from,omitempty"` // the environment variable or file that set the value
+}
+
+// EffectiveConfig is the configuration an Observer uses, keyed by the path of each value in a configuration file.
+// Secrets, such as the tracing headers and the password in the database connection string, are redacted.
+type EffectiveConfig map[string]ConfigValue
+
+// String lists the values in order of their keys, with where each one came from
+func (e EffectiveConfig) String() string {
+	keys := make([]string, 0, len(e))
+	width := 0
+	for k := range e {
+		keys = append(keys, k)
+		width = max(width, len(k))
+	}
+	sort.Strings(keys)
+
+	b := strings.Builder{}
+	for _, k := range keys {
+		v := e[k]
+
+		source := v.Source
+		if v.From != "" {
+			source += " " + v.From
+		}
+
+		fmt.Fprintf(&b, "%-*s = %s (%s)\n", width, k, v.Value, source)
+	}
+
+	return b.String()
+}
+
+// ResolveConfig returns the configuration that New would use with the options, without creating an Observer. Like New,
+// it loads the configuration with LoadConfig unless one is passed with WithConfig.
+func ResolveConfig(opts ...Option) (effective EffectiveConfig, fault error) {
+	user := &settings{}
+	for _, opt := range opts {
+		opt(user)
+	}
+
+	cfg := user.cfg
+	if cfg == nil {
+		loaded, err := LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		cfg = loaded
+	}
+
+	s := user
+	if pre := configOptions(cfg); len(pre) != 0 {
+		s = &settings{}
+		for _, opt := range append(pre, opts...) {
+			opt(s)
+		}
+	}
+
+	if s.level != nil {
+		cfg = levelConfig{Configurator: cfg, level: *s.level}
+	}
+
+	return effectiveConfig(cfg, s, user), nil
+}
+
+// EffectiveConfig returns the configuration the observer is using, and where each value came from
+func (o *Observer) EffectiveConfig() EffectiveConfig {
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+
+	user := &settings{}
+	for _, opt := range o.opts {
+		opt(user)
+	}
+
+	return effectiveConfig(o.cfg, o.settings, user)
+}
+
+// ConfigHandler returns a handler that responds with the observer's effective configuration, as JSON or as text if the
+// request has format=text in its query
+func (o *Observer) ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		effective := o.EffectiveConfig()
+
+		if r.URL.Query().Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(effective.String()))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(effective)
+	})
+}
+
+// effectiveConfig describes the configuration and settings, with the secrets redacted. The values set by user (the
+// options passed to New) came from code.
+func effectiveConfig(cfg Configurator, s *settings, user *settings) EffectiveConfig {
+	values := reloadableValues(cfg, s)
+	for k, v := range restartValues(cfg, s) {
+		values[k] = v
+	}
+
+	headers := make([]string, 0, len(s.traceHeaders))
+	for k := range s.traceHeaders {
+		headers = append(headers, k+"="+RedactedValue)
+	}
+	sort.Strings(headers)
+	values["tracing.headers"] = strings.Join(headers, ",")
+
+	values["db.constr"] = db.MaskDSN(values["db.constr"])
+	values["tracing.url"] = db.MaskDSN(values["tracing.url"])
+
+	values["tracing.mode"] = tracingMode(cfg, s)
+
+	if s.traceFile.MaxSize <= 0 {
+		values["tracing.file.max_size"] = strconv.Itoa(DefaultFileMaxSize)
+	}
+
+	if s.traceFile.MaxBackups <= 0 {
+		values["tracing.file.max_backups"] = strconv.Itoa(DefaultFileMaxBackups)
+	}
+
+	if values["tracing.protocol"] == "" {
+		values["tracing.protocol"] = ProtocolHTTPProtobuf
+	}
+
+	if values["tracing.compression"] == "" {
+		values["tracing.compression"] = CompressionGzip
+	}
+
+	if s.traceTimeout == 0 {
+		values["tracing.timeout"] = "10s"
+	}
+
+	if s.handler != nil {
+		values["log.format"] = "custom handler"
+		values["log.sinks"] = "custom handler"
+	}
+
+	if s.exporter != nil {
+		values["tracing.url"] = "custom exporter"
+	}
+
+	if s.pool != nil {
+		values["db.constr"] = "connection pool"
+	}
+
+	// the values the options passed to New set came from code, and the others from the configuration
+	code := map[string]bool{
+		"log.level":                     user.level != nil,
+		"log.format":                    user.format != "" || user.handler != nil,
+		"log.sinks":                     len(user.sinks) != 0 || user.handler != nil || user.output != nil,
+		"log.levels":                    user.packageLevels != nil,
+		"redaction.rules":               user.redaction != nil,
+		"tracing.sampler":               user.sampler != nil,
+		"tracing.tail_sampling":         user.tailSampling != nil,
+		"tracing.headers":               user.traceHeaders != nil,
+		"tracing.exporter":              user.traceExporter != "",
+		"tracing.mode":                  user.exporter != nil,
+		"tracing.file.path":             user.traceFile.Path != "",
+		"tracing.file.max_size":         user.traceFile.MaxSize != 0,
+		"tracing.file.max_backups":      user.traceFile.MaxBackups != 0,
+		"tracing.protocol":              user.traceProtocol != "",
+		"tracing.timeout":               user.traceTimeout != 0,
+		"tracing.compression":           user.compression != "",
+		"tracing.tls.ca_file":           user.traceTLS.CAFile != "",
+		"tracing.tls.cert_file":         user.traceTLS.CertFile != "",
+		"tracing.tls.key_file":          user.traceTLS.KeyFile != "",
+		"tracing.tls.skip_verify":       user.traceTLS.SkipVerify,
+		"tracing.propagators":           user.propagator != nil,
+		"tracing.baggage_fields":        user.baggageFields != nil,
+		"tracing.error_status_severity": user.errorStatusSeverity != "",
+		"tracing.url":                   user.exporter != nil,
+		"db.constr":                     user.pool != nil,
+		"db.migrate":                    user.skipMigrations,
+	}
+
+	if user.resource != nil {
+		for _, k := range []string{"service.name", "service.version", "service.namespace", "service.instance_id", "environment", "tracing.resource_attributes"} {
+			code[k] = true
+		}
+	}
+
+	sources := configSources(cfg)
+
+	effective := EffectiveConfig{}
+	for k, v := range values {
+		cv := ConfigValue{Value: v, Source: SourceDefault}
+
+		switch src, ok := sources[k]; {
+		case code[k]:
+			cv.Source = SourceCode
+		case ok:
+			cv.Source, cv.From = src.Source, src.From
+		}
+
+		effective[k] = cv
+	}
+
+	return effective
+}
+
+// configuratorKeys are the keys of the values that the Configurator interface covers
+var configuratorKeys = []string{"log.level", "tracing.url", "db.constr", "service.name", "log.trim_modules", "log.trim_paths"}
+
+// identityKeys are the keys of the values that the IdentityConfigurator interface covers
+var identityKeys = []string{"environment", "service.version", "service.namespace", "service.instance_id"}
+
+// exporterKeys are the keys of the values that the ExporterConfigurator interface covers
+var exporterKeys = []string{"tracing.exporter", "tracing.file.path", "tracing.file.max_size", "tracing.file.max_backups", "tracing.protocol", "tracing.headers", "tracing.timeout", "tracing.compression", "tracing.tls.ca_file", "tracing.tls.cert_file", "tracing.tls.key_file", "tracing.tls.skip_verify"}
+
+// configSources returns where the values of the configuration came from, keyed by their path. The values of
+// Configurator (and IdentityConfigurator and ExporterConfigurator) implementations other than Configuration came from code.
+func configSources(cfg Configurator) map[string]ConfigValue {
+	if lc, ok := cfg.(levelConfig); ok {
+		cfg = lc.Configurator
+	}
+
+	c, ok := cfg.(*Configuration)
+	if !ok || c.sources == nil {
+		keys := configuratorKeys
+		if _, ok := cfg.(IdentityConfigurator); ok {
+			keys = append(slices.Clone(keys), identityKeys...)
+		}
+		if _, ok := cfg.(ExporterConfigurator); ok {
+			keys = append(slices.Clone(keys), exporterKeys...)
+		}
+
+		sources := map[string]ConfigValue{}
+		for _, k := range keys {
+			sources[k] = ConfigValue{Source: SourceCode}
+		}
+
+		return sources
+	}
+
+	return c.sources
+}
+
+// envVar is an environment variable that sets a value of the configuration. If attr is set, the variable only sets the
+// value if it has that attribute (for OTEL_RESOURCE_ATTRIBUTES), and a configuration file takes precedence over it.
+type envVar struct {
+	name string
+	attr string
+}
+
+// configEnvVars are the environment variables that set each value of the configuration, in order of precedence
+var configEnvVars = map[string]envVarList{
+	"log.level":                     {{name: "LOG_LEVEL"}},
+	"log.trim_modules":              {{name: "TRIM_MODULES"}},
+	"log.trim_paths":                {{name: "TRIM_PATHS"}},
+	"service.name":                  {{name: "OTEL_SERVICE_NAME"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "service.name"}},
+	"service.version":               {{name: "SERVICE_VERSION"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "service.version"}},
+	"service.namespace":             {{name: "SERVICE_NAMESPACE"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "service.namespace"}},
+	"service.instance_id":           {{name: "SERVICE_INSTANCE_ID"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "service.instance.id"}},
+	"environment":                   {{name: "DEPLOYMENT_ENVIRONMENT"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "deployment.environment.name"}, {name: "OTEL_RESOURCE_ATTRIBUTES", attr: "deployment.environment"}},
+	"tracing.url":                   {{name: "OTEL_URL"}, {name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}, {name: "OTEL_EXPORTER_OTLP_ENDPOINT"}},
+	"tracing.exporter":              {{name: "OTEL_TRACES_EXPORTER"}},
+	"tracing.file.path":             {{name: TracesFileEnv}},
+	"tracing.protocol":              {{name: "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"}, {name: "OTEL_EXPORTER_OTLP_PROTOCOL"}},
+	"tracing.headers":               {{name: "OTEL_EXPORTER_OTLP_TRACES_HEADERS"}, {name: "OTEL_EXPORTER_OTLP_TRACES_HEADERS_FILE"}, {name: "OTEL_EXPORTER_OTLP_HEADERS"}, {name: "OTEL_EXPORTER_OTLP_HEADERS_FILE"}},
+	"tracing.timeout":               {{name: "OTEL_EXPORTER_OTLP_TRACES_TIMEOUT"}, {name: "OTEL_EXPORTER_OTLP_TIMEOUT"}},
+	"tracing.compression":           {{name: "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION"}, {name: "OTEL_EXPORTER_OTLP_COMPRESSION"}},
+	"tracing.tls.ca_file":           {{name: "OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE"}, {name: "OTEL_EXPORTER_OTLP_CERTIFICATE"}},
+	"tracing.tls.cert_file":         {{name: "OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE"}, {name: "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"}},
+	"tracing.tls.key_file":          {{name: "OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY"}, {name: "OTEL_EXPORTER_OTLP_CLIENT_KEY"}},
+	"tracing.resource_attributes":   {{name: "OTEL_RESOURCE_ATTRIBUTES"}},
+	"tracing.sampler":               {{name: "OTEL_TRACES_SAMPLER"}},
+	"tracing.propagators":           {{name: "OTEL_PROPAGATORS"}},
+	"tracing.baggage_fields":        {{name: BaggageFieldsEnv}},
+	"tracing.error_status_severity": {{name: ErrorStatusSeverityEnv}},
+	"tracing.disabled":              {{name: "OTEL_SDK_DISABLED"}},
+	"db.constr":                     {{name: "DB_CONSTR"}, {name: "DB_CONSTR_FILE"}, {name: "DB_HOST"}, {name: "DB_PASSWORD"}, {name: "DB_PASSWORD_FILE"}},
+}
+
+type envVarList []envVar
+
+// setBy returns the environment variable that sets the value, if there is one. Only the variables that take precedence
+// over a configuration file are considered if overridesFile is true, and only the others if it is false.
+func (vars envVarList) setBy(overridesFile bool) (name string, ok bool) {
+	for _, v := range vars {
+		if (v.attr == "") != overridesFile {
+			continue
+		}
+
+		value := os.Getenv(v.name)
+		if value == "" {
+			continue
+		}
+
+		if v.attr != "" {
+			attrs, err := parseKeyValues(value)
+			if err != nil || attrs[v.attr] == "" {
+				continue
+			}
+		}
+
+		return v.name, true
+	}
+
+	return "", false
+}
+
+// envSources returns where the values of a configuration loaded from the environment variables came from. The values
+// in fileKeys came from the file at path, unless they were overridden by an environment variable.
+func envSources(path string, fileKeys map[string]bool) map[string]ConfigValue {
+	sources := map[string]ConfigValue{}
+
+	for k, vars := range configEnvVars {
+		if name, ok := vars.setBy(true); ok {
+			sources[k] = ConfigValue{Source: SourceEnv, From: name}
+			continue
+		}
+
+		if fileKeys[k] {
+			sources[k] = ConfigValue{Source: SourceFile, From: path}
+			continue
+		}
+
+		if name, ok := vars.setBy(false); ok {
+			sources[k] = ConfigValue{Source: SourceEnv, From: name}
+		}
+	}
+
+	for k := range fileKeys {
+		if _, ok := sources[k]; !ok {
+			sources[k] = ConfigValue{Source: SourceFile, From: path}
+		}
+	}
+
+	return sources
+}
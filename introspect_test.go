@@ -0,0 +1,109 @@
+package go11y_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_COMPRESSION", "none")
+
+	path := writeConfigFile(t, "go11y.yaml", `
+service:
+  name: checkout
+log:
+  level: info
+tracing:
+  url: https://collector:4318/v1/traces
+  headers:
+    authorization: Bearer abc123
+  sampling:
+    ratio: 0.5
+db:
+  host: db
+  user: go11y
+  password_file: `+writeSecret(t, "hunter2")+`
+  migrate: false
+`)
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	effective, err := go11y.ResolveConfig(
+		go11y.WithConfig(cfg),
+		go11y.WithFormat(go11y.FormatText),
+		go11y.WithTraceExporter(tracetest.NewInMemoryExporter()),
+	)
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+
+	expected := map[string]go11y.ConfigValue{
+		"log.level":           {Value: "WARN", Source: go11y.SourceEnv, From: "LOG_LEVEL"},
+		"log.format":          {Value: go11y.FormatText, Source: go11y.SourceCode},
+		"log.trim_modules":    {Value: "", Source: go11y.SourceDefault},
+		"service.name":        {Value: "checkout", Source: go11y.SourceFile, From: path},
+		"tracing.url":         {Value: "custom exporter", Source: go11y.SourceCode},
+		"tracing.headers":     {Value: "authorization=" + go11y.RedactedValue, Source: go11y.SourceFile, From: path},
+		"tracing.compression": {Value: go11y.CompressionNone, Source: go11y.SourceEnv, From: "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION"},
+		"tracing.sampler":     {Value: "ParentBased{root:TraceIDRatioBased{0.5},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}", Source: go11y.SourceFile, From: path},
+		"db.constr":           {Value: "postgres://go11y:xxxxx@db/", Source: go11y.SourceFile, From: path},
+		"db.migrate":          {Value: "false", Source: go11y.SourceFile, From: path},
+	}
+
+	for k, exp := range expected {
+		if got := effective[k]; got != exp {
+			t.Errorf("expected %s to be %+v, got %+v", k, exp, got)
+		}
+	}
+
+	if strings.Contains(effective.String(), "hunter2") || strings.Contains(effective.String(), "abc123") {
+		t.Errorf("expected the secrets to be redacted, got %s", effective)
+	}
+}
+
+func TestConfigHandler(t *testing.T) {
+	_, o, err := go11y.New(context.Background(),
+		go11y.WithConfig(go11y.CreateConfig(go11y.LevelInfo, "", "", "checkout", nil, nil)),
+		go11y.WithOutput(&syncBuffer{}),
+		go11y.WithTraceExporter(tracetest.NewInMemoryExporter()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		o.ConfigHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+		effective := go11y.EffectiveConfig{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &effective); err != nil {
+			t.Fatalf("could not unmarshal the configuration: %v", err)
+		}
+
+		exp := go11y.ConfigValue{Value: "checkout", Source: go11y.SourceCode}
+		if effective["service.name"] != exp {
+			t.Errorf("expected service.name to be %+v, got %+v", exp, effective["service.name"])
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		o.ConfigHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config?format=text", nil))
+
+		if !strings.Contains(rec.Body.String(), "service.name") || !strings.Contains(rec.Body.String(), "= checkout (code)") {
+			t.Errorf("expected the configuration as text, got %s", rec.Body.String())
+		}
+	})
+}
@@ -1,8 +1,12 @@
 package go11y
 
 import (
+	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -15,23 +19,171 @@ const (
 	LevelFatal   = slog.Level(12)
 )
 
+// OTelSeverity is a severity number as defined by the OpenTelemetry log data model
+type OTelSeverity int
+
+const (
+	OTelSeverityTrace  OTelSeverity = 1
+	OTelSeverityDebug  OTelSeverity = 5
+	OTelSeverityInfo   OTelSeverity = 9
+	OTelSeverityInfo2  OTelSeverity = 10
+	OTelSeverityWarn   OTelSeverity = 13
+	OTelSeverityError  OTelSeverity = 17
+	OTelSeverityFatal  OTelSeverity = 21
+	OTelSeverityFatal4 OTelSeverity = 24
+)
+
+type levelDefinition struct {
+	name     string
+	level    slog.Level
+	severity OTelSeverity
+}
+
+type levelRegistry struct {
+	mu      sync.RWMutex
+	byName  map[string]levelDefinition
+	byLevel map[slog.Level]levelDefinition
+	ordered []slog.Level
+}
+
+var levels = newLevelRegistry()
+
+func newLevelRegistry() *levelRegistry {
+	r := &levelRegistry{
+		byName:  map[string]levelDefinition{},
+		byLevel: map[slog.Level]levelDefinition{},
+	}
+
+	r.register("DEVELOP", LevelDevelop, OTelSeverityTrace)
+	r.register("DEBUG", LevelDebug, OTelSeverityDebug)
+	r.register("INFO", LevelInfo, OTelSeverityInfo)
+	r.register("NOTICE", LevelNotice, OTelSeverityInfo2)
+	r.register("WARN", LevelWarning, OTelSeverityWarn, "warning")
+	r.register("ERR", LevelError, OTelSeverityError, "error")
+	r.register("FATAL", LevelFatal, OTelSeverityFatal)
+
+	return r
+}
+
+func (r *levelRegistry) register(name string, level slog.Level, severity OTelSeverity, aliases ...string) {
+	def := levelDefinition{
+		name:     strings.ToUpper(name),
+		level:    level,
+		severity: severity,
+	}
+
+	r.byLevel[level] = def
+	for _, n := range append([]string{name}, aliases...) {
+		r.byName[strings.ToLower(n)] = def
+	}
+
+	if !slices.Contains(r.ordered, level) {
+		r.ordered = append(r.ordered, level)
+		slices.Sort(r.ordered)
+	}
+}
+
+// below returns the definition of the nearest registered level at or below the level, or the lowest registered level
+// if there are none below it
+func (r *levelRegistry) below(level slog.Level) levelDefinition {
+	idx, found := slices.BinarySearch(r.ordered, level)
+	if found {
+		return r.byLevel[level]
+	}
+
+	if idx == 0 {
+		return r.byLevel[r.ordered[0]]
+	}
+
+	return r.byLevel[r.ordered[idx-1]]
+}
+
+// RegisterLevel adds a named level, such as TRACE or AUDIT, that is parsed by ParseLevelStrict, rendered by all the go11y
+// handlers and mapped to the given OpenTelemetry severity. The name is rendered in upper case and matched case-insensitively,
+// as are the optional aliases. It returns an error if the name or level is already registered to a different level or name.
+func RegisterLevel(name string, level slog.Level, severity OTelSeverity, aliases ...string) (fault error) {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("level name cannot be empty")
+	}
+
+	if strings.ContainsAny(name, "+- ") {
+		return fmt.Errorf("level name '%s' cannot contain '+', '-' or spaces", name)
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if def, ok := levels.byLevel[level]; ok && def.name != strings.ToUpper(name) {
+		return fmt.Errorf("level %d is already registered as '%s'", level, def.name)
+	}
+
+	for _, n := range append([]string{name}, aliases...) {
+		if def, ok := levels.byName[strings.ToLower(n)]; ok && def.level != level {
+			return fmt.Errorf("level name '%s' is already registered for level %d", n, def.level)
+		}
+	}
+
+	levels.register(name, level, severity, aliases...)
+
+	return nil
+}
+
+// ParseLevelStrict converts a level name (e.g. "debug" or "WARN") to a slog.Level, returning an error if the name is not
+// registered. Offsets from a registered level, in the same "NAME+N" form used to render unregistered levels, are also accepted.
+func ParseLevelStrict(level string) (parsed slog.Level, fault error) {
+	name := strings.ToLower(strings.TrimSpace(level))
+	offset := 0
+
+	if idx := strings.IndexAny(name, "+-"); idx > 0 {
+		o, err := strconv.Atoi(name[idx:])
+		if err != nil {
+			return LevelDebug, fmt.Errorf("invalid level offset in '%s': %w", level, err)
+		}
+
+		name, offset = name[:idx], o
+	}
+
+	levels.mu.RLock()
+	def, ok := levels.byName[name]
+	levels.mu.RUnlock()
+
+	if !ok {
+		return LevelDebug, fmt.Errorf("unknown log level '%s'", level)
+	}
+
+	return def.level + slog.Level(offset), nil
+}
+
+// ParseLevel converts a level name to a slog.Level, defaulting to LevelDebug if the name is unknown.
+// Use ParseLevelStrict to find out if the name was not recognised.
 func ParseLevel(level string) slog.Level {
-	switch strings.ToLower(level) {
-	case "develop":
-		return LevelDevelop // Custom level for development, not used in production
-	case "debug":
-		return LevelDebug
-	case "info":
-		return LevelInfo
-	case "notice":
-		return LevelNotice
-	case "warning", "warn":
-		return LevelWarning
-	case "error":
-		return LevelError
-	case "fatal":
-		return LevelFatal
-	default:
+	l, err := ParseLevelStrict(level)
+	if err != nil {
 		return LevelDebug // default to debug if unknown level
 	}
+
+	return l
+}
+
+// LevelName returns the name used to render the level in log output. Levels that are not registered are rendered as an
+// offset from the nearest registered level below them (e.g. "WARN+1").
+func LevelName(level slog.Level) string {
+	levels.mu.RLock()
+	def := levels.below(level)
+	levels.mu.RUnlock()
+
+	if def.level == level {
+		return def.name
+	}
+
+	return fmt.Sprintf("%s%+d", def.name, level-def.level)
+}
+
+// LevelSeverity returns the OpenTelemetry severity number for the level. Levels that are not registered use the severity
+// of the nearest registered level below them.
+func LevelSeverity(level slog.Level) OTelSeverity {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	return levels.below(level).severity
 }
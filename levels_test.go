@@ -0,0 +1,168 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+func TestParseLevelStrict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected slog.Level
+		fails    bool
+	}{
+		{name: "develop", expected: go11y.LevelDevelop},
+		{name: "DEBUG", expected: go11y.LevelDebug},
+		{name: "info", expected: go11y.LevelInfo},
+		{name: "notice", expected: go11y.LevelNotice},
+		{name: "warn", expected: go11y.LevelWarning},
+		{name: "warning", expected: go11y.LevelWarning},
+		{name: "err", expected: go11y.LevelError},
+		{name: "error", expected: go11y.LevelError},
+		{name: "fatal", expected: go11y.LevelFatal},
+		{name: "WARN+1", expected: go11y.LevelWarning + 1},
+		{name: "wraning", fails: true},
+		{name: "", fails: true},
+		{name: "info+x", fails: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level, err := go11y.ParseLevelStrict(tc.name)
+			if tc.fails {
+				if err == nil {
+					t.Fatalf("expected an error parsing '%s', got level %d", tc.name, level)
+				}
+
+				if go11y.ParseLevel(tc.name) != go11y.LevelDebug {
+					t.Errorf("expected ParseLevel to fall back to debug for '%s'", tc.name)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error parsing '%s': %v", tc.name, err)
+			}
+
+			if level != tc.expected {
+				t.Errorf("expected '%s' to parse to %d, got %d", tc.name, tc.expected, level)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalidLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "wraning")
+
+	if _, err := go11y.LoadConfig(); err == nil {
+		t.Fatal("expected an error loading config with an invalid LOG_LEVEL")
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	levelTrace := go11y.LevelDevelop - 4
+	levelAudit := go11y.LevelNotice + 1
+
+	if err := go11y.RegisterLevel("trace", levelTrace, go11y.OTelSeverityTrace); err != nil {
+		t.Fatalf("failed to register trace level: %v", err)
+	}
+
+	if err := go11y.RegisterLevel("audit", levelAudit, go11y.OTelSeverityInfo2+1, "compliance"); err != nil {
+		t.Fatalf("failed to register audit level: %v", err)
+	}
+
+	t.Run("conflicts", func(t *testing.T) {
+		if err := go11y.RegisterLevel("audit", levelAudit, go11y.OTelSeverityInfo2+1); err != nil {
+			t.Errorf("expected re-registering the same level to succeed: %v", err)
+		}
+
+		if err := go11y.RegisterLevel("security", levelAudit, go11y.OTelSeverityWarn); err == nil {
+			t.Error("expected an error registering a second name for the same level")
+		}
+
+		if err := go11y.RegisterLevel("info", go11y.LevelInfo+1, go11y.OTelSeverityInfo); err == nil {
+			t.Error("expected an error registering an existing name for another level")
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		for name, expected := range map[string]slog.Level{"TRACE": levelTrace, "audit": levelAudit, "compliance": levelAudit} {
+			level, err := go11y.ParseLevelStrict(name)
+			if err != nil {
+				t.Fatalf("failed to parse '%s': %v", name, err)
+			}
+
+			if level != expected {
+				t.Errorf("expected '%s' to parse to %d, got %d", name, expected, level)
+			}
+		}
+	})
+
+	t.Run("names", func(t *testing.T) {
+		for level, expected := range map[slog.Level]string{
+			levelTrace:               "TRACE",
+			levelTrace - 1:           "TRACE-1",
+			go11y.LevelDevelop - 2:   "TRACE+2",
+			go11y.LevelDevelop - 100: "TRACE-96",
+			go11y.LevelDevelop:       "DEVELOP",
+			go11y.LevelDebug:         "DEBUG",
+			go11y.LevelInfo - 1:      "DEBUG+3",
+			go11y.LevelInfo:          "INFO",
+			go11y.LevelNotice:        "NOTICE",
+			levelAudit:               "AUDIT",
+			go11y.LevelWarning:       "WARN",
+			go11y.LevelWarning + 1:   "WARN+1",
+			go11y.LevelError:         "ERR",
+			go11y.LevelFatal:         "FATAL",
+			go11y.LevelFatal + 4:     "FATAL+4",
+		} {
+			if name := go11y.LevelName(level); name != expected {
+				t.Errorf("expected level %d to be named '%s', got '%s'", level, expected, name)
+			}
+		}
+	})
+
+	t.Run("severity", func(t *testing.T) {
+		if s := go11y.LevelSeverity(levelAudit); s != go11y.OTelSeverityInfo2+1 {
+			t.Errorf("expected audit to map to severity %d, got %d", go11y.OTelSeverityInfo2+1, s)
+		}
+
+		if s := go11y.LevelSeverity(go11y.LevelError + 1); s != go11y.OTelSeverityError {
+			t.Errorf("expected ERR+1 to map to severity %d, got %d", go11y.OTelSeverityError, s)
+		}
+	})
+
+	t.Run("render", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+
+		cfg := go11y.CreateConfig(levelTrace, "", "", "", nil, nil)
+
+		_, o, err := go11y.Initialise(context.Background(), cfg, buf)
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+		defer func() {
+			o.Close()
+		}()
+
+		o.Log(levelAudit, "audited")
+
+		line := map[string]any{}
+		if err := json.Unmarshal([]byte(strings.Join(withoutTracingMode(buf.String()), "\n")), &line); err != nil {
+			t.Fatalf("failed to parse log output %q: %v", buf.String(), err)
+		}
+
+		if line["level"] != "AUDIT" {
+			t.Errorf("expected the level to be rendered as AUDIT, got %v", line["level"])
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// LinkedSpan gets the Observer from the context and starts a new tracing span with links to the span contexts, such as
+// a batch consumer's span linking to the traces that produced each message. The span is in the trace of the context (or
+// starts a new one if there isn't one), and the span contexts that aren't valid are skipped.
+// The linking equivalent of Span()
+func LinkedSpan(ctx context.Context, tracer otelTrace.Tracer, spanName string, spanKind otelTrace.SpanKind, links ...otelTrace.SpanContext) (ctxWithSpan context.Context, observer *Observer) {
+	ctx, o := Get(ctx)
+
+	otelLinks := make([]otelTrace.Link, 0, len(links))
+	for _, sc := range links {
+		if sc.IsValid() {
+			otelLinks = append(otelLinks, otelTrace.Link{SpanContext: sc})
+		}
+	}
+
+	ctx, span := tracer.Start(ctx, spanName, otelTrace.WithSpanKind(spanKind), otelTrace.WithLinks(otelLinks...))
+
+	o.span = span
+	o.spans = append(o.spans, span)
+
+	return context.WithValue(ctx, obsKeyInstance, o), o
+}
+
+// FormatSpanContext returns the span context as a W3C traceparent, such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01, to be stored with a job and restored with ParseSpanContext.
+// It returns an empty string if the span context isn't valid.
+func FormatSpanContext(sc otelTrace.SpanContext) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(otelTrace.ContextWithSpanContext(context.Background(), sc), carrier)
+
+	return carrier.Get("traceparent")
+}
+
+// ParseSpanContext returns the span context of a W3C traceparent, such as one from FormatSpanContext. The span context
+// is remote, so it can be linked to with LinkedSpan, or continued with otelTrace.ContextWithRemoteSpanContext.
+func ParseSpanContext(traceparent string) (sc otelTrace.SpanContext, fault error) {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceparent})
+
+	sc = otelTrace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return otelTrace.SpanContext{}, fmt.Errorf("invalid span context '%s'", traceparent)
+	}
+
+	return sc, nil
+}
+
+// SpanContextHeaders returns the headers the propagator sends for the span context and baggage of the context, to be
+// stored with a job (such as in a database row or message attributes) and restored with ContextFromHeaders
+func SpanContextHeaders(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	return carrier
+}
+
+// ContextFromHeaders returns the context with the span context and baggage of the headers from SpanContextHeaders, so a
+// background worker can continue the trace that scheduled the job. Spans started with the context are in that trace,
+// with the span that scheduled the job as their parent.
+func ContextFromHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
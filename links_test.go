@@ -0,0 +1,173 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestParseSpanContext(t *testing.T) {
+	testCases := []struct {
+		name        string
+		traceparent string
+		expSampled  bool
+		expErr      bool
+	}{
+		{
+			name:        "sampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expSampled:  true,
+		},
+		{
+			name:        "not sampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		},
+		{
+			name:        "empty",
+			traceparent: "",
+			expErr:      true,
+		},
+		{
+			name:        "zero trace ID",
+			traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			expErr:      true,
+		},
+		{
+			name:        "not a traceparent",
+			traceparent: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, err := go11y.ParseSpanContext(tc.traceparent)
+			if tc.expErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid span context") {
+					t.Errorf("expected an error about the invalid span context, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to parse the span context: %v", err)
+			}
+
+			if !sc.IsRemote() || sc.IsSampled() != tc.expSampled {
+				t.Errorf("expected a remote span context sampled %t, got %+v", tc.expSampled, sc)
+			}
+
+			if actual := go11y.FormatSpanContext(sc); actual != tc.traceparent {
+				t.Errorf("expected the span context to be formatted as %s, got %s", tc.traceparent, actual)
+			}
+		})
+	}
+
+	if actual := go11y.FormatSpanContext(otelTrace.SpanContext{}); actual != "" {
+		t.Errorf("expected an invalid span context to be formatted as an empty string, got %s", actual)
+	}
+}
+
+func TestSpanContextHeaders(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	ctx, err = o.SetBaggage(ctx, "tenant_id", "acme")
+	if err != nil {
+		t.Fatalf("failed to set baggage: %v", err)
+	}
+
+	ctx, scheduler := o.Tracer("test").Start(ctx, "schedule job", otelTrace.WithSpanKind(go11y.SpanKindProducer))
+	headers := go11y.SpanContextHeaders(ctx)
+	scheduler.End()
+
+	if headers["traceparent"] == "" || headers["baggage"] == "" {
+		t.Fatalf("expected the traceparent and baggage headers, got %v", headers)
+	}
+
+	// the worker restores the context from the job, long after the scheduler's span has ended
+	ctx = go11y.ContextFromHeaders(context.Background(), headers)
+
+	if tenant := baggage.FromContext(ctx).Member("tenant_id").Value(); tenant != "acme" {
+		t.Errorf("expected the baggage to be restored, got %q", tenant)
+	}
+
+	_, o = go11y.Span(ctx, o.Tracer("test"), "run job", go11y.SpanKindConsumer)
+	o.End()
+	o.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	worker := spans[1]
+	if worker.Name != "run job" || worker.SpanContext.TraceID() != scheduler.SpanContext().TraceID() || worker.Parent.SpanID() != scheduler.SpanContext().SpanID() {
+		t.Errorf("expected the worker's span to continue the scheduler's trace, got %+v", worker)
+	}
+}
+
+func TestLinkedSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	// each message is produced in its own trace, and its span context is stored with it
+	stored := []string{}
+	for range 3 {
+		_, producer := o.Tracer("test").Start(context.Background(), "produce", otelTrace.WithSpanKind(go11y.SpanKindProducer))
+		stored = append(stored, go11y.FormatSpanContext(producer.SpanContext()))
+		producer.End()
+	}
+
+	links := []otelTrace.SpanContext{{}} // an invalid span context is skipped
+	for _, s := range stored {
+		sc, err := go11y.ParseSpanContext(s)
+		if err != nil {
+			t.Fatalf("failed to parse the span context: %v", err)
+		}
+		links = append(links, sc)
+	}
+
+	_, o = go11y.LinkedSpan(ctx, o.Tracer("test"), "consume batch", go11y.SpanKindConsumer, links...)
+	o.End()
+	o.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+
+	consumer := spans[3]
+	if consumer.Name != "consume batch" || consumer.SpanKind != go11y.SpanKindConsumer {
+		t.Fatalf("expected the consumer's span, got %s (%s)", consumer.Name, consumer.SpanKind)
+	}
+
+	if len(consumer.Links) != 3 {
+		t.Fatalf("expected 3 links, got %d", len(consumer.Links))
+	}
+
+	for i, l := range consumer.Links {
+		if l.SpanContext.TraceID() != spans[i].SpanContext.TraceID() || l.SpanContext.SpanID() != spans[i].SpanContext.SpanID() {
+			t.Errorf("expected link %d to be to the producer's span %s, got %s", i, spans[i].SpanContext.SpanID(), l.SpanContext.SpanID())
+		}
+
+		if l.SpanContext.TraceID() == consumer.SpanContext.TraceID() {
+			t.Errorf("expected the consumer's span to be in a trace of its own")
+		}
+	}
+}
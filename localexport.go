@@ -0,0 +1,207 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	otelExportTrace "go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	protoTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// The exporters the spans can be sent to
+const (
+	ExporterOTLP   = "otlp"   // the collector at the OpenTelemetry URL
+	ExporterStdout = "stdout" // OTLP/JSON lines on stdout
+	ExporterFile   = "file"   // OTLP/JSON lines in a rotating file
+)
+
+// TracesFileEnv is the environment variable the file exporter reads the path of the file from
+const TracesFileEnv = "GO11Y_TRACES_FILE"
+
+// The defaults for the file exporter
+const (
+	DefaultFileMaxSize    = 100 << 20
+	DefaultFileMaxBackups = 5
+)
+
+// FileExporterConfig configures the file the spans are written to by the file exporter
+type FileExporterConfig struct {
+	Path       string // the file the spans are appended to
+	MaxSize    int64  // the size in bytes the file can grow to before it is rotated, DefaultFileMaxSize if it is zero
+	MaxBackups int    // how many rotated files are kept, DefaultFileMaxBackups if it is zero
+}
+
+// NewWriterExporter creates an exporter that writes each batch of spans to w as a line of OTLP/JSON, which is the
+// same as the body of an OTLP/HTTP export request, so the lines can be replayed into a collector later
+func NewWriterExporter(ctx context.Context, w io.Writer) (exporter otelSDKTrace.SpanExporter, fault error) {
+	return otelExportTrace.New(ctx, &writerClient{w: w})
+}
+
+// NewStdoutExporter creates an exporter that writes the spans to stdout as lines of OTLP/JSON, for debugging without a
+// collector
+func NewStdoutExporter(ctx context.Context) (exporter otelSDKTrace.SpanExporter, fault error) {
+	return NewWriterExporter(ctx, os.Stdout)
+}
+
+// NewFileExporter creates an exporter that appends the spans to a file as lines of OTLP/JSON. When the file would grow
+// larger than the maximum size, it is renamed with .1 added to its name (and the older files to .2, .3 and so on) and a
+// new file is started.
+func NewFileExporter(ctx context.Context, cfg FileExporterConfig) (exporter otelSDKTrace.SpanExporter, fault error) {
+	if cfg.Path == "" {
+		return nil, errors.New("the path of the file is required")
+	}
+
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultFileMaxSize
+	}
+
+	if cfg.MaxBackups <= 0 {
+		cfg.MaxBackups = DefaultFileMaxBackups
+	}
+
+	f, err := openRotatingFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return otelExportTrace.New(ctx, &writerClient{w: f, closer: f})
+}
+
+// writerClient writes the spans to a writer instead of sending them to a collector
+type writerClient struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// Start does nothing, as the writer is already open
+func (c *writerClient) Start(ctx context.Context) (fault error) {
+	return nil
+}
+
+// Stop closes the writer, if it needs closing
+func (c *writerClient) Stop(ctx context.Context) (fault error) {
+	if c.closer == nil {
+		return nil
+	}
+
+	return c.closer.Close()
+}
+
+// UploadTraces writes the spans as a line of OTLP/JSON
+func (c *writerClient) UploadTraces(ctx context.Context, spans []*protoTrace.ResourceSpans) (fault error) {
+	data, err := otlpJSON(spans)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write the spans: %w", err)
+	}
+
+	return nil
+}
+
+// rotatingFile appends to a file, and rotates it when it would grow larger than the maximum size. Each write goes to a
+// single file, so lines are never split between them.
+type rotatingFile struct {
+	mu   sync.Mutex
+	cfg  FileExporterConfig
+	f    *os.File
+	size int64
+}
+
+func openRotatingFile(cfg FileExporterConfig) (rf *rotatingFile, fault error) {
+	rf = &rotatingFile{cfg: cfg}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (r *rotatingFile) open() (fault error) {
+	f, err := os.OpenFile(r.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open span file '%s': %w", r.cfg.Path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not open span file '%s': %w", r.cfg.Path, err)
+	}
+
+	r.f, r.size = f, info.Size()
+
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (n int, fault error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.cfg.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// rotate renames the file (and the older rotated files), dropping the oldest, and starts a new one. If the files can't
+// be renamed, the file is opened again so the next write can try again, rather than every write failing after it.
+func (r *rotatingFile) rotate() (fault error) {
+	err := r.f.Close()
+	if err != nil {
+		err = fmt.Errorf("could not close span file '%s': %w", r.cfg.Path, err)
+	} else {
+		err = r.renameFiles()
+	}
+
+	if err != nil {
+		return errors.Join(err, r.open())
+	}
+
+	return r.open()
+}
+
+// renameFiles renames the file to the first backup, and each backup to the next, removing the oldest
+func (r *rotatingFile) renameFiles() (fault error) {
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", r.cfg.Path, i) }
+
+	if err := os.Remove(backup(r.cfg.MaxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove the oldest span file: %w", err)
+	}
+
+	for i := r.cfg.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not rotate span file: %w", err)
+		}
+	}
+
+	if err := os.Rename(r.cfg.Path, backup(1)); err != nil {
+		return fmt.Errorf("could not rotate span file: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rotatingFile) Close() (fault error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.f.Close()
+}
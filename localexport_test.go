@@ -0,0 +1,182 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// readLines returns the lines of the file, failing the test if any of them isn't an OTLP/JSON export request
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s: %v", path, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, line := range lines {
+		req := struct {
+			ResourceSpans []json.RawMessage `json:"resourceSpans"`
+		}{}
+		if err := json.Unmarshal([]byte(line), &req); err != nil || len(req.ResourceSpans) == 0 {
+			t.Errorf("expected an OTLP/JSON export request, got %s", line)
+		}
+	}
+
+	return lines
+}
+
+func TestWriterExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	exporter, err := go11y.NewWriterExporter(context.Background(), buf)
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+
+	for _, name := range []string{"first", "second"} {
+		_, span := tp.Tracer("test").Start(context.Background(), name)
+		span.End()
+	}
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("could not write spans: %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 2 {
+		t.Fatalf("expected a line for each export, got %d", len(lines))
+	}
+
+	if !strings.Contains(lines[1], `"name":"second"`) {
+		t.Errorf("expected the second line to have the second span, got %s", lines[1])
+	}
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+	exporter, err := go11y.NewFileExporter(context.Background(), go11y.FileExporterConfig{Path: path, MaxSize: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+
+	for _, name := range []string{"one", "two", "three", "four"} {
+		_, span := tp.Tracer("test").Start(context.Background(), name)
+		span.End()
+	}
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	expected := map[string]string{path: "four", path + ".1": "three", path + ".2": "two"}
+	for file, name := range expected {
+		lines := readLines(t, file)
+		if len(lines) != 1 || !strings.Contains(lines[0], `"name":"`+name+`"`) {
+			t.Errorf("expected %s to have only the %s span, got %v", filepath.Base(file), name, lines)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files to be kept")
+	}
+}
+
+func TestFileExporterRotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+	// the oldest backup can't be removed while it is a directory with a file in it, so the rotation fails
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o755); err != nil {
+		t.Fatalf("could not create the directory: %v", err)
+	}
+
+	exporter, err := go11y.NewFileExporter(context.Background(), go11y.FileExporterConfig{Path: path, MaxSize: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+
+	export := func(name string) {
+		_, span := tp.Tracer("test").Start(context.Background(), name)
+		span.End()
+	}
+
+	export("one")
+	export("two") // dropped, as the file can't be rotated
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatalf("could not remove the directory: %v", err)
+	}
+
+	export("three")
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	expected := map[string]string{path: "three", path + ".1": "one"}
+	for file, name := range expected {
+		lines := readLines(t, file)
+		if len(lines) != 1 || !strings.Contains(lines[0], `"name":"`+name+`"`) {
+			t.Errorf("expected %s to have only the %s span, got %v", filepath.Base(file), name, lines)
+		}
+	}
+}
+
+func TestLoadConfigFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "file")
+	t.Setenv(go11y.TracesFileEnv, path)
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	_, span := o.Tracer("test").Start(ctx, "written")
+	span.End()
+	o.Close()
+
+	lines := readLines(t, path)
+	if !strings.Contains(lines[0], `"name":"written"`) {
+		t.Errorf("expected the span to be written to the file, got %v", lines)
+	}
+
+	t.Setenv(go11y.TracesFileEnv, "")
+
+	if _, err := go11y.LoadConfig(); err == nil || !strings.Contains(err.Error(), go11y.TracesFileEnv) {
+		t.Errorf("expected an error about the missing %s, got %v", go11y.TracesFileEnv, err)
+	}
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  exporter: file\n"))
+	if err == nil || !strings.Contains(err.Error(), "tracing.file.path") {
+		t.Errorf("expected an error about the missing tracing.file.path, got %v", err)
+	}
+}
@@ -2,67 +2,60 @@ package go11y
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+
+	"github.com/jsnfwlr/go11y/internal/testhooks"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
 // Develop records an event on the tracing span if it is available and logs a develop message via the observer (if the observer's log-level allows).
 // This is intended for use during development and may be too verbose or could leak secrets in production use, and should be filtered out in such environments.
 func (o *Observer) Develop(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelDevelop, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelDevelop, msg, ephemeralArgs...)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if logged {
+		o.recordOnSpan(r, nil)
 	}
 }
 
 // Debug records an event on the tracing span if it is available and logs a debug message via the observer (if the observer's log-level allows).
 func (o *Observer) Debug(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelDebug, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelDebug, msg, ephemeralArgs...)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if logged {
+		o.recordOnSpan(r, nil)
 	}
 }
 
 // Info records an event on the tracing span if it is available and logs an information message via the observer (if the observer's log-level allows).
 func (o *Observer) Info(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelInfo, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelInfo, msg, ephemeralArgs...)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if logged {
+		o.recordOnSpan(r, nil)
 	}
 }
 
 // Notice records an event on the tracing span if it is available and logs a notice message via the observer (if the observer's log-level allows).
 func (o *Observer) Notice(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelNotice, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelNotice, msg, ephemeralArgs...)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if logged {
+		o.recordOnSpan(r, nil)
 	}
 }
 
 // Warning records an event on the tracing span if it is available and logs a warning message via the observer (if the observer's log-level allows).
 func (o *Observer) Warning(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelWarning, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelWarning, msg, ephemeralArgs...)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if logged {
+		o.recordOnSpan(r, nil)
 	}
 }
 
@@ -71,35 +64,58 @@ func (o *Observer) Warn(msg string, ephemeralArgs ...any) {
 	o.Warning(msg, ephemeralArgs...)
 }
 
+// Log records an event on the tracing span if it is available and logs a message at the given level via the observer (if the
+// observer's log-level allows). This is intended for use with the levels added with RegisterLevel.
+func (o *Observer) Log(level slog.Level, msg string, ephemeralArgs ...any) {
+	r, logged := o.log(o.context(), 3, level, msg, ephemeralArgs...)
+
+	if logged {
+		o.recordOnSpan(r, nil)
+	}
+}
+
 // Error records an error on the tracing span if it is available and logs an error message via the observer (if the observer's log-level allows), with the
-// specified severity level.
+// specified severity level. Errors at or above the error status severity (see WithErrorStatusSeverity) also set the status of the span to an error,
+// whether or not the log-level filtered the message out, as the status belongs to the trace rather than the logs.
 func (o *Observer) Error(msg string, err error, severity string, ephemeralArgs ...any) {
 	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", severity)
-	logged := o.log(context.Background(), 3, LevelError, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelError, msg, ephemeralArgs...)
+
+	if logged {
+		o.recordOnSpan(r, err)
+	}
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.RecordError(err)
+	if o.live == nil || int32(severityRank(severity)) >= o.live.errorStatus.Load() {
+		o.setErrorStatus(err)
 	}
 }
 
 // Fatal records an error on the tracing span if it is available and logs a fatal error message via the observer with the
-// highest severity level and then exits the application with a status code of 1.
+// highest severity level and then exits the application with a status code of 1 (unless a test has replaced the exit).
 func (o *Observer) Fatal(msg string, err error, ephemeralArgs ...any) {
 	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)
-	logged := o.log(context.Background(), 3, LevelFatal, msg, ephemeralArgs...)
+	r, logged := o.log(o.context(), 3, LevelFatal, msg, ephemeralArgs...)
+
+	if logged {
+		o.recordOnSpan(r, err)
+	}
+
+	o.setErrorStatus(err)
 
-	if logged && o.span != nil {
-		ephemeralArgs = append(o.stableArgs, ephemeralArgs...)
-		attrs := argsToAttributes(ephemeralArgs...)
-		o.span.SetAttributes(attrs...)
-		o.span.RecordError(err)
+	if o.exit != nil {
+		o.exit(1)
+		return
 	}
+
 	os.Exit(1)
 }
 
+func init() {
+	testhooks.SetExitFunc = func(observer any, exit func(code int)) {
+		observer.(*Observer).exit = exit
+	}
+}
+
 // Fatal logs a fatal error message with the highest severity level and then exits the application with a status code of 1.
 // This is intended to for use in situations where an Observer instance is not available such as in the main function before the observer has been initialised.
 func Fatal(msg string, err error, ephemeralArgs ...any) {
@@ -107,6 +123,59 @@ func Fatal(msg string, err error, ephemeralArgs ...any) {
 	cfg := CreateConfig(LevelFatal, "", "", "", nil, nil)
 	_, o, _ := Initialise(ctx, cfg, os.Stderr, nil)
 	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)
-	o.log(context.Background(), 3, LevelFatal, msg, ephemeralArgs...)
+	_, _ = o.log(context.Background(), 3, LevelFatal, msg, ephemeralArgs...)
 	os.Exit(1)
 }
+
+// context returns the context passed to the processors and handler by the logging methods, which carries the active span
+func (o *Observer) context() context.Context {
+	if o.span == nil {
+		return context.Background()
+	}
+
+	return otelTrace.ContextWithSpan(context.Background(), o.span)
+}
+
+// setErrorStatus sets the status of the active span (if there is one) to an error, with the redacted message of the
+// error as its description. The status is set even if the level filtered the error out of the logs.
+func (o *Observer) setErrorStatus(err error) {
+	if o.span == nil {
+		return
+	}
+
+	o.span.SetStatus(codes.Error, o.redactor.RedactString("error", err.Error()))
+}
+
+// recordOnSpan adds the stable args and the attributes of the processed record to the active span (if there is one), and
+// then adds the message as an event on the span, or records the error if there is one
+func (o *Observer) recordOnSpan(r slog.Record, err error) {
+	if o.span == nil {
+		return
+	}
+
+	args := slices.Clone(o.stableArgs)
+	r.Attrs(func(a slog.Attr) bool {
+		args = append(args, a.Key, a.Value.Resolve().Any())
+		return true
+	})
+
+	o.span.SetAttributes(o.attributes(args...)...)
+
+	if err != nil {
+		msg := o.redactor.RedactString("error", err.Error())
+		if msg == err.Error() {
+			o.span.RecordError(err, levelEventOption(r.Level))
+			return
+		}
+
+		// RecordError would leak the unredacted message, so build the same exception event with the redacted one
+		o.span.AddEvent("exception", levelEventOption(r.Level), otelTrace.WithAttributes(
+			otelAttribute.String("exception.type", fmt.Sprintf("%T", err)),
+			otelAttribute.String("exception.message", msg),
+		))
+
+		return
+	}
+
+	o.span.AddEvent(o.redactor.RedactMessage(r.Message), levelEventOption(r.Level))
+}
@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 
 	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/go11ytest"
+	"github.com/jsnfwlr/go11y/internal/testhooks"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestLoggingContext(t *testing.T) {
@@ -22,7 +28,8 @@ func TestLoggingContext(t *testing.T) {
 		t.Fatalf("failed to load config: %v", err)
 	}
 
-	ctx, o, err := go11y.Initialise(context.Background(), cfg, buf)
+	// where the test runs isn't detected, so the output matches the golden file in a container too
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(buf), go11y.WithResourceDetectors())
 	if err != nil {
 		t.Fatalf("failed to initialise observer: %v", err)
 	}
@@ -30,22 +37,138 @@ func TestLoggingContext(t *testing.T) {
 		o.Close()
 	}()
 
-	o.Fatal("Test Logging Context", errors.New("TestLoggingContext"), nil, "fatal", 1)
-	ctx, o = go11y.Extend(ctx, nil, "", go11y.FieldRequestID, uuid.New())
-	o.Info("TestLoggingContext", nil, "info", 1)
+	testhooks.SetExitFunc(o, func(int) {}) // log the fatal message without ending the test
+
+	o.Fatal("Test Logging Context", errors.New("TestLoggingContext"), "fatal", 1)
+	ctx, o = go11y.Extend(ctx, go11y.FieldRequestID, uuid.New())
+	o.Info("TestLoggingContext", "info", 1)
 	ctx = AddFieldsToLoggerInContext(t, ctx, go11y.FieldRequestMethod, "GET", go11y.FieldRequestPath, "/api/v1/test")
 	ctx, o = go11y.Get(ctx)
-	o.Info("TestLoggingContext", nil, "info", 2)
+	o.Info("TestLoggingContext", "info", 2)
 
-	// @TODO: read the buffer and check the output matches expected log format
-	// and content
+	go11ytest.Golden(t, "logging_context", buf.Bytes())
 }
 
 func AddFieldsToLoggerInContext(t *testing.T, ctx context.Context, args ...any) (modCtx context.Context) {
 	// Add fields to the logger in the context
 	c, o := go11y.Extend(ctx, args...)
 
-	o.Info("AddFieldsToLoggerInContext", nil, "info", 1)
+	o.Info("AddFieldsToLoggerInContext", "info", 1)
 
 	return c
 }
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name      string
+		threshold string
+		level     string
+		severity  string
+		fatal     bool
+		expError  bool
+	}{
+		{name: "lowest with the default", severity: go11y.SeverityLowest},
+		{name: "low with the default", severity: go11y.SeverityLow},
+		{name: "medium with the default", severity: go11y.SeverityMedium, expError: true},
+		{name: "highest with the default", severity: go11y.SeverityHighest, expError: true},
+		{name: "unknown severity", severity: "catastrophic", expError: true},
+		{name: "low with a low threshold", threshold: go11y.SeverityLow, severity: go11y.SeverityLow, expError: true},
+		{name: "medium with a high threshold", threshold: go11y.SeverityHigh, severity: go11y.SeverityMedium},
+		{name: "fatal with the highest threshold", threshold: go11y.SeverityHighest, fatal: true, expError: true},
+		{name: "medium filtered out by the level", level: "fatal", severity: go11y.SeverityMedium, expError: true},
+		{name: "low filtered out by the level", level: "fatal", severity: go11y.SeverityLow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.level != "" {
+				// the status is set whether or not the level lets the error into the logs
+				t.Setenv("LOG_LEVEL", tc.level)
+			}
+
+			exporter := tracetest.NewInMemoryExporter()
+
+			opts := []go11y.Option{go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter})}
+			if tc.threshold != "" {
+				opts = append(opts, go11y.WithErrorStatusSeverity(tc.threshold))
+			}
+
+			ctx, o, err := go11y.New(context.Background(), opts...)
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+
+			_, o = go11y.Span(ctx, o.Tracer("test"), "failing", go11y.SpanKindInternal)
+
+			if tc.fatal {
+				testhooks.SetExitFunc(o, func(int) {})
+				o.Fatal("could not start", errors.New("no config"))
+			} else {
+				o.Error("could not save", errors.New("disk full"), tc.severity)
+			}
+
+			o.End()
+			o.Close()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			if actual := spans[0].Status.Code == codes.Error; actual != tc.expError {
+				t.Errorf("expected the span status to be an error %t, got %+v", tc.expError, spans[0].Status)
+			}
+
+			if tc.expError && spans[0].Status.Description == "" {
+				t.Errorf("expected the error message as the description of the status")
+			}
+
+			if tc.level != "" && len(spans[0].Events) != 0 {
+				t.Errorf("expected the level to leave the error out of the span events, got %+v", spans[0].Events)
+			}
+		})
+	}
+}
+
+func TestErrorStatusSeverityConfig(t *testing.T) {
+	path := writeConfigFile(t, "go11y.yaml", "tracing:\n  error_status_severity: low\n")
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	if value := o.EffectiveConfig()["tracing.error_status_severity"]; value.Value != go11y.SeverityLow || value.Source != go11y.SourceFile {
+		t.Errorf("expected the error status severity from the file, got %+v", value)
+	}
+
+	t.Setenv(go11y.ErrorStatusSeverityEnv, go11y.SeverityHigh)
+
+	cfg, err = go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err = go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	if value := o.EffectiveConfig()["tracing.error_status_severity"]; value.Value != go11y.SeverityHigh || value.Source != go11y.SourceEnv {
+		t.Errorf("expected %s to take precedence over the file, got %+v", go11y.ErrorStatusSeverityEnv, value)
+	}
+
+	t.Setenv(go11y.ErrorStatusSeverityEnv, "")
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  error_status_severity: dire\n"))
+	if err == nil || !strings.Contains(err.Error(), "tracing.error_status_severity: must be lowest, low, medium, high or highest, got 'dire'") {
+		t.Errorf("expected an error about the invalid severity, got %v", err)
+	}
+}
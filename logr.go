@@ -0,0 +1,221 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+)
+
+// LogrLevels maps a logr verbosity onto a go11y level
+type LogrLevels func(v int) slog.Level
+
+// DefaultLogrLevels maps V(0) to Info, V(1) to Debug and anything more verbose to Develop
+func DefaultLogrLevels(v int) slog.Level {
+	switch {
+	case v <= 0:
+		return LevelInfo
+	case v == 1:
+		return LevelDebug
+	default:
+		return LevelDevelop
+	}
+}
+
+// OTelLogrLevels maps the verbosities used by OpenTelemetry's internal logger (warnings at V(1), info at V(4) and debug
+// at V(8)) onto the go11y levels
+func OTelLogrLevels(v int) slog.Level {
+	switch {
+	case v <= 1:
+		return LevelWarning
+	case v <= 4:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// Logr returns a logr.Logger that logs via the observer, for libraries that log through go-logr
+func (o *Observer) Logr() logr.Logger {
+	return logr.New(NewLogSink(o, DefaultLogrLevels))
+}
+
+// NewLogSink creates a logr.LogSink that logs via the observer, using levels to map the logr verbosity onto the go11y
+// levels (DefaultLogrLevels if levels is nil). Errors are logged at the error level with a medium severity.
+func NewLogSink(o *Observer, levels LogrLevels) logr.LogSink {
+	if levels == nil {
+		levels = DefaultLogrLevels
+	}
+
+	return &logSink{
+		handler:      o.Handler(),
+		levels:       levels,
+		maxVerbosity: -1,
+	}
+}
+
+type logSink struct {
+	handler      slog.Handler
+	levels       LogrLevels
+	maxVerbosity int // -1 for no limit
+	name         string
+	callDepth    int
+}
+
+func (s *logSink) Init(info logr.RuntimeInfo) {
+	s.callDepth = info.CallDepth
+}
+
+func (s *logSink) Enabled(level int) bool {
+	if s.maxVerbosity >= 0 && level > s.maxVerbosity {
+		return false
+	}
+
+	return s.handler.Enabled(context.Background(), s.levels(level))
+}
+
+func (s *logSink) Info(level int, msg string, keysAndValues ...any) {
+	if s.maxVerbosity >= 0 && level > s.maxVerbosity {
+		return
+	}
+
+	s.log(s.levels(level), msg, keysAndValues...)
+}
+
+func (s *logSink) Error(err error, msg string, keysAndValues ...any) {
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+
+	s.log(LevelError, msg, append(keysAndValues, "severity", SeverityMedium)...)
+}
+
+func (s *logSink) WithValues(keysAndValues ...any) logr.LogSink {
+	s2 := *s
+	s2.handler = s.handler.WithAttrs(argsToSlogAttrs(keysAndValues))
+
+	return &s2
+}
+
+func (s *logSink) WithName(name string) logr.LogSink {
+	s2 := *s
+	if s.name == "" {
+		s2.name = name
+	} else {
+		s2.name = s.name + "/" + name
+	}
+
+	return &s2
+}
+
+func (s *logSink) WithCallDepth(depth int) logr.LogSink {
+	s2 := *s
+	s2.callDepth += depth
+
+	return &s2
+}
+
+func (s *logSink) log(level slog.Level, msg string, keysAndValues ...any) {
+	ctx := context.Background()
+	if !s.handler.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// skip [runtime.Callers, this function, the LogSink method, logr.Logger's method] plus any wrappers of the logr.Logger
+	runtime.Callers(3+s.callDepth, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if s.name != "" {
+		r.AddAttrs(slog.String("logger", s.name))
+	}
+	r.Add(keysAndValues...)
+
+	_ = s.handler.Handle(ctx, r)
+}
+
+// argsToSlogAttrs converts key/value pairs into slog attributes, the same way slog.Record.Add does
+func argsToSlogAttrs(args []any) []slog.Attr {
+	r := slog.Record{}
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return attrs
+}
+
+// OTelErrorHandler returns an otel.ErrorHandler that logs the errors reported by the OpenTelemetry SDK (such as failed
+// exports) via the observer. Transient errors, such as timeouts and refused connections, are logged as warnings, and
+// all other errors are logged as errors with a medium severity.
+func (o *Observer) OTelErrorHandler() otel.ErrorHandler {
+	return otel.ErrorHandlerFunc(func(err error) {
+		if err == nil {
+			return
+		}
+
+		if IsTransient(err) {
+			_, _ = o.log(context.Background(), 4, LevelWarning, "OpenTelemetry error", "error", err.Error())
+			return
+		}
+
+		_, _ = o.log(context.Background(), 4, LevelError, "OpenTelemetry error", "error", err.Error(), "severity", SeverityMedium)
+	})
+}
+
+// transientMessages are the messages of errors that are likely to resolve themselves, for errors that are not wrapped
+var transientMessages = []string{
+	"connection refused",
+	"connection reset",
+	"deadline exceeded",
+	"i/o timeout",
+	"max retry time elapsed",
+	"no such host",
+	"too many requests",
+	"unavailable",
+}
+
+// IsTransient reports whether the error is likely to resolve itself, such as a timeout or a refused connection
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// installOTelDiagnostics routes OpenTelemetry's warnings and errors through the observer. Its info and debug messages
+// only describe its own configuration, so they are dropped; use NewLogSink with OTelLogrLevels to log them as well.
+func installOTelDiagnostics(o *Observer) {
+	sink := NewLogSink(o, OTelLogrLevels).(*logSink)
+	sink.maxVerbosity = 1
+
+	otel.SetLogger(logr.New(sink))
+	otel.SetErrorHandler(o.OTelErrorHandler())
+}
@@ -0,0 +1,79 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"github.com/jsnfwlr/go11y/go11ytest"
+	"go.opentelemetry.io/otel"
+)
+
+func TestLogr(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	logger := o.Logr().WithName("controller").WithValues("namespace", "default")
+
+	logger.Info("reconciled", "pod", "web-0")
+	logger.V(1).Info("cache hit")
+	logger.V(3).Info("details")
+	logger.Error(errors.New("boom"), "reconcile failed")
+
+	o.RequireLogged(go11y.LevelInfo, "reconciled", "logger", "controller", "namespace", "default", "pod", "web-0")
+	o.RequireLogged(go11y.LevelDebug, "cache hit")
+	o.RequireLogged(go11y.LevelDevelop, "details")
+	o.RequireLogged(go11y.LevelError, "reconcile failed", "error", "boom", "severity", go11y.SeverityMedium)
+}
+
+func TestOTelLogrLevels(t *testing.T) {
+	testCases := []struct {
+		v        int
+		expected string
+	}{
+		{v: 0, expected: "WARN"},
+		{v: 1, expected: "WARN"},
+		{v: 4, expected: "INFO"},
+		{v: 8, expected: "DEBUG"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("V%d", tc.v), func(t *testing.T) {
+			if got := go11y.LevelName(go11y.OTelLogrLevels(tc.v)); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestOTelErrorHandler(t *testing.T) {
+	o := go11ytest.NewObserver(t)
+
+	otel.Handle(fmt.Errorf("failed to export spans: %w", context.DeadlineExceeded))
+	otel.Handle(errors.New("failed to upload traces: 400 Bad Request"))
+
+	o.RequireLogged(go11y.LevelWarning, "OpenTelemetry error", "error", "failed to export spans: context deadline exceeded")
+	o.RequireLogged(go11y.LevelError, "OpenTelemetry error", "error", "failed to upload traces: 400 Bad Request", "severity", go11y.SeverityMedium)
+}
+
+func TestIsTransient(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "deadline", err: fmt.Errorf("export: %w", context.DeadlineExceeded), expected: true},
+		{name: "refused", err: errors.New("dial tcp 127.0.0.1:4318: connect: connection refused"), expected: true},
+		{name: "bad request", err: errors.New("400 Bad Request"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := go11y.IsTransient(tc.err); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package go11y
 
 import (
+	"bufio"
 	"context"
+	"net"
 	"net/http"
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -49,9 +52,60 @@ type Origin struct {
 	Path      string `json:"path"`
 }
 
+// statusRecorder records the status of the response written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	if !sr.wroteHeader {
+		sr.status, sr.wroteHeader = statusCode, true
+	}
+
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush sends the buffered data to the client, if the original ResponseWriter can flush, so streaming handlers still work
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original ResponseWriter, so http.ResponseController can reach its Flush and Hijack methods
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// hijackRecorder is a statusRecorder for a ResponseWriter that can be hijacked, so handlers that take over the
+// connection (e.g. for websockets) still can. It is only used when the original ResponseWriter is an http.Hijacker.
+type hijackRecorder struct {
+	*statusRecorder
+}
+
+// Hijack lets the handler take over the connection from the original ResponseWriter
+func (hr hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hr.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// recordStatus wraps the ResponseWriter in a statusRecorder, keeping its ability to be hijacked
+func recordStatus(w http.ResponseWriter) (rec *statusRecorder, wrapped http.ResponseWriter) {
+	rec = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	if _, ok := w.(http.Hijacker); ok {
+		return rec, hijackRecorder{rec}
+	}
+
+	return rec, rec
+}
+
 // LogRequest is a middleware that logs incoming HTTP requests and their details
 // It extracts tracing information from the request headers and starts a new span for the request
 // It also logs the request details using go11y, adding the go11y Observer to the request context in the process
+// The baggage members whose keys are in the baggage fields (see WithBaggageFields) are added to the logs and the span
+// The status of the response is set on the span, which is marked as an error if it is a 5xx status
 func LogRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Log&Trace the request
@@ -63,7 +117,7 @@ func LogRequest(next http.Handler) http.Handler {
 
 		tracer := otel.Tracer(requestID)
 
-		ctx = Reset(ctx)
+		ctx, o := Get(Reset(ctx))
 
 		args := []any{
 			"origin", Origin{
@@ -74,10 +128,15 @@ func LogRequest(next http.Handler) http.Handler {
 			},
 		}
 
+		// the baggage members in the baggage fields, such as the tenant, are logged and traced with everything else
+		args = append(args, baggageArgs(ctx, o.baggageFields())...)
+
 		// tracer
 		opts := []trace.SpanStartOption{
 			trace.WithSpanKind(trace.SpanKindServer),
-			trace.WithAttributes(argsToAttributes(args...)...),
+			trace.WithAttributes(o.attributes(args...)...),
+			// the sampler sees the attributes the span starts with, so the sampling rules can match the method and path
+			trace.WithAttributes(otelSemConv.HTTPRequestMethodKey.String(r.Method), otelSemConv.URLPathKey.String(r.URL.Path)),
 		}
 
 		ctx, span := tracer.Start(ctx, "HTTP "+r.Method+" "+r.URL.Path, opts...)
@@ -87,14 +146,17 @@ func LogRequest(next http.Handler) http.Handler {
 			FieldTraceID, span.SpanContext().TraceID(),
 		)
 
-		ctx, o := Extend(ctx, args...)
+		ctx, o = Extend(ctx, args...)
 
 		o.Debug("request received", span)
 
 		r = r.WithContext(ctx)
 
-		// Call the next handler
-		next.ServeHTTP(w, r)
+		// Call the next handler, recording the status of the response for the span
+		rec, wrapped := recordStatus(w)
+		next.ServeHTTP(wrapped, r)
+
+		recordHTTPStatus(span, trace.SpanKindServer, rec.status, nil)
 
 		// Log the response
 		// log.Printf("Response sent for: %s %s", r.Method, r.URL.Path)
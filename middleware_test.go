@@ -1,6 +1,147 @@
 package go11y_test
 
-import "testing"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
 
 func TestMiddleware(t *testing.T) {
 }
+
+func TestLogRequestStatus(t *testing.T) {
+	testCases := []struct {
+		name         string
+		handler      http.HandlerFunc
+		expStatus    int64
+		expErrorType string
+	}{
+		{
+			name:      "implicit ok",
+			handler:   func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("ok")) },
+			expStatus: http.StatusOK,
+		},
+		{
+			name:      "not found",
+			handler:   func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) },
+			expStatus: http.StatusNotFound,
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.WriteHeader(http.StatusOK) // superfluous, so the first status is kept
+			},
+			expStatus:    http.StatusServiceUnavailable,
+			expErrorType: "503",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+
+			_, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+
+			srv := httptest.NewServer(go11y.LogRequest(tc.handler))
+
+			resp, err := http.Get(srv.URL + "/orders")
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			srv.Close()
+			o.Close()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			attrs := map[string]string{}
+			var status int64
+			for _, kv := range spans[0].Attributes {
+				attrs[string(kv.Key)] = kv.Value.Emit()
+				if kv.Key == otelSemConv.HTTPResponseStatusCodeKey {
+					status = kv.Value.AsInt64()
+				}
+			}
+
+			if status != tc.expStatus {
+				t.Errorf("expected the status code to be %d, got %d", tc.expStatus, status)
+			}
+
+			if actual := attrs[string(otelSemConv.ErrorTypeKey)]; actual != tc.expErrorType {
+				t.Errorf("expected the error type to be %q, got %q", tc.expErrorType, actual)
+			}
+
+			if isError := spans[0].Status.Code == codes.Error; isError != (tc.expErrorType != "") {
+				t.Errorf("expected the span status to be an error %t, got %+v", tc.expErrorType != "", spans[0].Status)
+			}
+		})
+	}
+}
+
+func TestLogRequestWriter(t *testing.T) {
+	_, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	var flushed, hijackable bool
+	handler := go11y.LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("streamed"))
+
+		f, ok := w.(http.Flusher)
+		if ok {
+			f.Flush()
+		}
+		flushed = ok
+
+		_, hijackable = w.(http.Hijacker)
+	}))
+
+	t.Run("server", func(t *testing.T) {
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/stream")
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		_ = resp.Body.Close()
+
+		if !flushed {
+			t.Errorf("expected the handler to be able to flush the response")
+		}
+
+		if !hijackable {
+			t.Errorf("expected the handler to be able to hijack the connection")
+		}
+	})
+
+	t.Run("recorder", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+		if !flushed || !rec.Flushed {
+			t.Errorf("expected the response to be flushed to the recorder")
+		}
+
+		if hijackable {
+			t.Errorf("expected the handler not to be able to hijack a recorder, which can't be hijacked")
+		}
+	})
+}
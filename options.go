@@ -1,15 +1,197 @@
 package go11y
 
 import (
+	"io"
 	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/propagation"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
-func defaultOptions(cfg Configurator) *slog.HandlerOptions {
+// defaultOptions creates the options for the handlers go11y creates, which follow the level and trim lists of the live
+// configuration
+func defaultOptions(live *liveConfig) *slog.HandlerOptions {
 	ho := &slog.HandlerOptions{
 		AddSource:   true,
-		Level:       cfg.LogLevel(),
-		ReplaceAttr: defaultReplacer(cfg.TrimModules(), cfg.TrimPaths()),
+		Level:       live.level,
+		ReplaceAttr: live.replaceAttr,
 	}
 
 	return ho
 }
+
+// Option configures the Observer created by New
+type Option func(s *settings)
+
+// settings holds the values set by the options passed to New
+type settings struct {
+	cfg                 Configurator
+	output              io.Writer
+	level               *slog.Level
+	handler             slog.Handler
+	exporter            otelSDKTrace.SpanExporter
+	resource            *otelResource.Resource
+	detectors           []otelResource.Detector
+	pool                *pgxpool.Pool
+	skipMigrations      bool
+	stableArgs          []any
+	processors          []Processor
+	format              string
+	sinks               []SinkConfig
+	packageLevels       map[string]slog.Level
+	redaction           []RedactionRule
+	sampler             otelSDKTrace.Sampler
+	tailSampling        *TailSamplingConfig
+	traceHeaders        map[string]string
+	propagator          propagation.TextMapPropagator
+	baggageFields       []string
+	errorStatusSeverity string
+
+	// set from the standard OpenTelemetry environment variables
+	traceExporter   string
+	traceFile       FileExporterConfig
+	traceProtocol   string
+	traceTimeout    time.Duration
+	compression     string
+	traceTLS        TLSConfig
+	resourceAttrs   map[string]string
+	tracingDisabled bool
+}
+
+// WithConfig uses the configuration instead of loading it from the environment variables with LoadConfig
+func WithConfig(cfg Configurator) Option {
+	return func(s *settings) {
+		s.cfg = cfg
+	}
+}
+
+// WithOutput writes the logs as JSON to the writer instead of stdout. It is ignored if WithHandler is also used.
+func WithOutput(w io.Writer) Option {
+	return func(s *settings) {
+		s.output = w
+	}
+}
+
+// WithLevel overrides the log level from the configuration
+func WithLevel(level slog.Level) Option {
+	return func(s *settings) {
+		s.level = &level
+	}
+}
+
+// WithHandler sends the log records to the handler instead of a JSON handler, such as the one returned by
+// NewSyslogHandler. The handler is responsible for its own level filtering and formatting.
+func WithHandler(handler slog.Handler) Option {
+	return func(s *settings) {
+		s.handler = handler
+	}
+}
+
+// WithTraceExporter sends the spans to the exporter instead of the OpenTelemetry URL from the configuration
+func WithTraceExporter(exporter otelSDKTrace.SpanExporter) Option {
+	return func(s *settings) {
+		s.exporter = exporter
+	}
+}
+
+// WithResource describes the service with the resource instead of only the service name from the configuration
+func WithResource(res *otelResource.Resource) Option {
+	return func(s *settings) {
+		s.resource = res
+	}
+}
+
+// WithDB stores the roundtrip requests using the pool instead of connecting with the connection string from the
+// configuration. The pool is not closed by the observer.
+func WithDB(pool *pgxpool.Pool) Option {
+	return func(s *settings) {
+		s.pool = pool
+	}
+}
+
+// WithoutMigrations skips migrating the database, for when the migrations are run separately
+func WithoutMigrations() Option {
+	return func(s *settings) {
+		s.skipMigrations = true
+	}
+}
+
+// WithStableArgs adds the args to every log record and span
+func WithStableArgs(args ...any) Option {
+	return func(s *settings) {
+		s.stableArgs = append(s.stableArgs, args...)
+	}
+}
+
+// WithProcessors adds the processors to the observer, in the same way as AddProcessors
+func WithProcessors(processors ...Processor) Option {
+	return func(s *settings) {
+		s.processors = append(s.processors, processors...)
+	}
+}
+
+// WithFormat writes the logs in the format (FormatJSON or FormatText) instead of JSON. It is ignored if WithHandler is
+// also used.
+func WithFormat(format string) Option {
+	return func(s *settings) {
+		s.format = format
+	}
+}
+
+// WithSinks writes the logs to each of the sinks instead of the output. It is ignored if WithHandler is also used.
+func WithSinks(sinks ...SinkConfig) Option {
+	return func(s *settings) {
+		s.sinks = sinks
+	}
+}
+
+// WithPackageLevels sets the log level for the records logged by the functions in each package (and its sub-packages),
+// keyed by the package's import path. Records from other packages use the configured log level.
+func WithPackageLevels(levels map[string]slog.Level) Option {
+	return func(s *settings) {
+		s.packageLevels = levels
+	}
+}
+
+// WithRedactionRules redacts the logs, spans and stored requests with the rules instead of DefaultRedactionRules
+func WithRedactionRules(rules ...RedactionRule) Option {
+	return func(s *settings) {
+		s.redaction = rules
+	}
+}
+
+// WithSampler decides which traces are sampled with the sampler instead of sampling every trace
+func WithSampler(sampler otelSDKTrace.Sampler) Option {
+	return func(s *settings) {
+		s.sampler = sampler
+	}
+}
+
+// WithTraceHeaders sends the headers with each request to the OpenTelemetry URL, such as for authentication
+func WithTraceHeaders(headers map[string]string) Option {
+	return func(s *settings) {
+		s.traceHeaders = headers
+	}
+}
+
+// WithPropagator sets the propagator used to send and receive the trace context and baggage in requests, instead of the
+// W3C trace context and baggage propagators (or those set with OTEL_PROPAGATORS)
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(s *settings) {
+		s.propagator = propagator
+	}
+}
+
+// levelConfig overrides the log level of a Configurator
+type levelConfig struct {
+	Configurator
+	level slog.Level
+}
+
+// LogLevel returns the overridden log level
+func (c levelConfig) LogLevel() slog.Level {
+	return c.level
+}
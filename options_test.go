@@ -0,0 +1,82 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// keepSpans stops the in-memory exporter from discarding its spans when the observer is closed
+type keepSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepSpans) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := tracetest.NewInMemoryExporter()
+
+	cfg := go11y.CreateConfig(go11y.LevelDebug, "", "", "options", nil, nil)
+	res := otelResource.NewSchemaless(otelSemConv.ServiceNameKey.String("from-resource"))
+
+	ctx, o, err := go11y.New(context.Background(),
+		go11y.WithConfig(cfg),
+		go11y.WithOutput(buf),
+		go11y.WithLevel(go11y.LevelWarning),
+		go11y.WithTraceExporter(keepSpans{exporter}),
+		go11y.WithResource(res),
+		go11y.WithStableArgs("tenant", "acme"),
+		go11y.WithProcessors(go11y.Enrich("build_version", "1.2.3")),
+	)
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	_, o = go11y.Span(ctx, o.Tracer("test"), "options", go11y.SpanKindInternal)
+
+	o.Info("filtered out")
+	o.Warning("logged")
+	o.End()
+	o.Close()
+
+	t.Run("logs", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("expected 1 log line, got %d: %s", len(lines), buf.String())
+		}
+
+		line := map[string]any{}
+		if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+			t.Fatalf("could not unmarshal log line: %v", err)
+		}
+
+		expected := map[string]any{"msg": "logged", "tenant": "acme", "build_version": "1.2.3"}
+		for k, v := range expected {
+			if line[k] != v {
+				t.Errorf("expected %s to be %v, got %v", k, v, line[k])
+			}
+		}
+	})
+
+	t.Run("spans", func(t *testing.T) {
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		name, ok := spans[0].Resource.Set().Value(otelSemConv.ServiceNameKey)
+		if !ok || name.AsString() != "from-resource" {
+			t.Errorf("expected the service name from the resource, got %v", name.AsString())
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package go11y
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// The protocols the spans can be exported with
+const (
+	ProtocolHTTPProtobuf = "http/protobuf"
+	ProtocolHTTPJSON     = "http/json"
+	ProtocolGRPC         = "grpc"
+)
+
+// The compressions the spans can be exported with
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// otelEnv holds the values of the standard OpenTelemetry environment variables that go11y supports. See
+// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/
+type otelEnv struct {
+	endpoint            string // the URL the spans are exported to, for OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT
+	endpointIsBase      bool   // the endpoint is from OTEL_EXPORTER_OTLP_ENDPOINT, the base URL for all the signals
+	protocol            string
+	headers             map[string]string
+	timeout             time.Duration
+	compression         string
+	tls                 TLSConfig
+	exporter            string
+	filePath            string
+	resourceAttrs       map[string]string
+	sampler             otelSDKTrace.Sampler
+	propagator          propagation.TextMapPropagator
+	baggageFields       []string // from GO11Y_BAGGAGE_FIELDS, which isn't a standard variable but is read with them
+	errorStatusSeverity string   // from GO11Y_ERROR_STATUS_SEVERITY, which is read with them in the same way
+	disabled            bool
+	warnings            []string // the problems with the values that are ignored rather than failing
+}
+
+// loadOTelEnv reads the standard OpenTelemetry environment variables. The signal specific variables (such as
+// OTEL_EXPORTER_OTLP_TRACES_HEADERS) take precedence over the general ones (such as OTEL_EXPORTER_OTLP_HEADERS). All the
+// problems with the values are returned together. The headers can also be read from the file named by
+// OTEL_EXPORTER_OTLP_(TRACES_)HEADERS_FILE.
+func loadOTelEnv() (oe otelEnv, fault error) {
+	var errs []error
+
+	lookup := func(names ...string) (name, value string) {
+		for _, n := range names {
+			if v, ok := os.LookupEnv(n); ok && strings.TrimSpace(v) != "" {
+				return n, strings.TrimSpace(v)
+			}
+		}
+
+		return "", ""
+	}
+
+	if name, v := lookup("OTEL_SDK_DISABLED"); name != "" {
+		// like the SDK, a value that isn't a boolean leaves the SDK enabled rather than failing
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			oe.warnings = append(oe.warnings, fmt.Sprintf("%s: must be true or false, got '%s', so tracing is enabled", name, v))
+		}
+		oe.disabled = disabled
+	}
+
+	if name, v := lookup("OTEL_TRACES_EXPORTER"); name != "" {
+		exporter := v
+		if v == "console" {
+			exporter = ExporterStdout // the name the specification uses
+		}
+
+		if err := validateExporter(exporter); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		oe.exporter = exporter
+	}
+
+	_, oe.filePath = lookup(TracesFileEnv)
+
+	if name, v := lookup("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"); name != "" {
+		if err := validateProtocol(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		oe.protocol = v
+	}
+
+	if name, v := lookup("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"); name != "" {
+		if u, err := url.Parse(v); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: must be a URL, got '%s'", name, v))
+		}
+
+		oe.endpoint, oe.endpointIsBase = v, name == "OTEL_EXPORTER_OTLP_ENDPOINT"
+	}
+
+	// the headers often hold credentials, so they can also be read from a file with the _FILE variables
+	v, name, err := lookupSecret("OTEL_EXPORTER_OTLP_TRACES_HEADERS")
+	if err == nil && v == "" {
+		v, name, err = lookupSecret("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case v != "":
+		headers, err := parseKeyValues(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		oe.headers = headers
+	}
+
+	if name, v := lookup("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "OTEL_EXPORTER_OTLP_TIMEOUT"); name != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms < 0 {
+			errs = append(errs, fmt.Errorf("%s: must be a number of milliseconds, got '%s'", name, v))
+		}
+		oe.timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	if name, v := lookup("OTEL_EXPORTER_OTLP_TRACES_COMPRESSION", "OTEL_EXPORTER_OTLP_COMPRESSION"); name != "" {
+		switch v {
+		case CompressionGzip, CompressionNone:
+			oe.compression = v
+		default:
+			errs = append(errs, fmt.Errorf("%s: must be %s or %s, got '%s'", name, CompressionGzip, CompressionNone, v))
+		}
+	}
+
+	_, oe.tls.CAFile = lookup("OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE", "OTEL_EXPORTER_OTLP_CERTIFICATE")
+
+	certName, certFile := lookup("OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE", "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE")
+	keyName, keyFile := lookup("OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY", "OTEL_EXPORTER_OTLP_CLIENT_KEY")
+
+	switch {
+	case certName != "" && keyName == "":
+		errs = append(errs, fmt.Errorf("%s: must be set with OTEL_EXPORTER_OTLP_CLIENT_KEY", certName))
+	case keyName != "" && certName == "":
+		errs = append(errs, fmt.Errorf("%s: must be set with OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", keyName))
+	}
+	oe.tls.CertFile, oe.tls.KeyFile = certFile, keyFile
+
+	if name, v := lookup("OTEL_RESOURCE_ATTRIBUTES"); name != "" {
+		attrs, err := parseKeyValues(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		oe.resourceAttrs = attrs
+	}
+
+	if name, v := lookup("OTEL_TRACES_SAMPLER"); name != "" {
+		_, arg := lookup("OTEL_TRACES_SAMPLER_ARG")
+
+		sampler, err := parseSampler(v, arg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		oe.sampler = sampler
+	}
+
+	if name, v := lookup("OTEL_PROPAGATORS"); name != "" {
+		propagator, err := NewPropagator(strings.Split(v, ",")...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("OTEL_PROPAGATORS: %w", err))
+		}
+		oe.propagator = propagator
+	}
+
+	if name, v := lookup(BaggageFieldsEnv); name != "" {
+		oe.baggageFields = splitBaggageFields(v)
+		if err := validateBaggageFields(oe.baggageFields); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if name, v := lookup(ErrorStatusSeverityEnv); name != "" {
+		if err := validateSeverity(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		oe.errorStatusSeverity = v
+	}
+
+	return oe, errors.Join(errs...)
+}
+
+// validateProtocol returns an error if the spans can't be exported with the protocol
+func validateProtocol(protocol string) (fault error) {
+	switch protocol {
+	case ProtocolHTTPProtobuf, ProtocolHTTPJSON, ProtocolGRPC:
+		return nil
+	default:
+		return fmt.Errorf("must be %s, %s or %s, got '%s'", ProtocolHTTPProtobuf, ProtocolHTTPJSON, ProtocolGRPC, protocol)
+	}
+}
+
+// parseKeyValues parses a comma separated list of key=value pairs with URL encoded values, as used by
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_RESOURCE_ATTRIBUTES
+func parseKeyValues(s string) (kv map[string]string, fault error) {
+	kv = map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("must be a list of key=value pairs, got '%s'", pair)
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("the value for '%s' is not URL encoded correctly: %w", k, err)
+		}
+
+		kv[k] = value
+	}
+
+	return kv, nil
+}
+
+// tracesEndpoint returns the URL the spans are exported to with the protocol, which is only known once the configuration
+// file and the environment variables have been merged. The general endpoint is the base URL for all the signals, so the
+// path for traces is added to it for HTTP.
+func (oe otelEnv) tracesEndpoint(protocol string) string {
+	if !oe.endpointIsBase || protocol == ProtocolGRPC {
+		return oe.endpoint
+	}
+
+	return strings.TrimSuffix(oe.endpoint, "/") + "/v1/traces"
+}
+
+// parseSampler creates the sampler named by OTEL_TRACES_SAMPLER, using OTEL_TRACES_SAMPLER_ARG as the ratio for the
+// ratio based samplers (1 if it is not set)
+func parseSampler(name, arg string) (sampler otelSDKTrace.Sampler, fault error) {
+	ratio := 1.0
+
+	if arg != "" && strings.HasSuffix(name, "traceidratio") {
+		r, err := strconv.ParseFloat(arg, 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: must be a ratio between 0 and 1, got '%s'", arg)
+		}
+		ratio = r
+	}
+
+	sampler, err := NewSampler(name, ratio)
+	if err != nil {
+		return nil, fmt.Errorf("OTEL_TRACES_SAMPLER: %w", err)
+	}
+
+	return sampler, nil
+}
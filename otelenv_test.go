@@ -0,0 +1,318 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel"
+	collectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLoadConfigOTelEnv(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		expURL  string
+		expName string
+		expErrs []string
+	}{
+		{
+			name:   "general endpoint gets the traces path",
+			env:    map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/"},
+			expURL: "http://collector:4318/v1/traces",
+		},
+		{
+			name:   "general endpoint is used as is for grpc",
+			env:    map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"},
+			expURL: "http://collector:4317",
+		},
+		{
+			name: "traces endpoint takes precedence",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://traces:4318/custom",
+			},
+			expURL: "http://traces:4318/custom",
+		},
+		{
+			name: "OTEL_URL takes precedence",
+			env: map[string]string{
+				"OTEL_URL":                           "http://go11y:4318/v1/traces",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://traces:4318/custom",
+			},
+			expURL: "http://go11y:4318/v1/traces",
+		},
+		{
+			name:    "service name from resource attributes",
+			env:     map[string]string{"OTEL_RESOURCE_ATTRIBUTES": "service.name=checkout,deployment.environment=prod"},
+			expName: "checkout",
+		},
+		{
+			name: "invalid values",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "carrier-pigeon",
+				"OTEL_EXPORTER_OTLP_HEADERS":  "novalue",
+				"OTEL_EXPORTER_OTLP_TIMEOUT":  "soon",
+				"OTEL_TRACES_SAMPLER":         "sometimes",
+				"OTEL_PROPAGATORS":            "tracecontext,smoke-signal",
+			},
+			expErrs: []string{
+				"OTEL_EXPORTER_OTLP_PROTOCOL: must be http/protobuf, http/json or grpc",
+				"OTEL_EXPORTER_OTLP_HEADERS: must be a list of key=value pairs",
+				"OTEL_EXPORTER_OTLP_TIMEOUT: must be a number of milliseconds",
+				"OTEL_TRACES_SAMPLER: unsupported sampler 'sometimes'",
+				"OTEL_PROPAGATORS: unsupported propagator 'smoke-signal'",
+			},
+		},
+		{
+			name: "invalid traces endpoint",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "collector:4318/v1/traces",
+			},
+			expErrs: []string{
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: must be a URL, got 'collector:4318/v1/traces'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := go11y.LoadConfig()
+
+			if len(tc.expErrs) != 0 {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+
+				for _, e := range tc.expErrs {
+					if !strings.Contains(err.Error(), e) {
+						t.Errorf("expected the error to contain %q, got:\n%v", e, err)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if cfg.URL() != tc.expURL {
+				t.Errorf("expected url %q, got %q", tc.expURL, cfg.URL())
+			}
+
+			if cfg.ServiceName() != tc.expName {
+				t.Errorf("expected service name %q, got %q", tc.expName, cfg.ServiceName())
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileOTelEndpoint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		protocol string
+		expURL   string
+	}{
+		{name: "http from the file", protocol: "http/protobuf", expURL: "http://collector:4318/v1/traces"},
+		{name: "grpc from the file", protocol: "grpc", expURL: "http://collector:4317"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the general endpoint only gets the traces path once the protocol from the file is known
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", strings.TrimSuffix(tc.expURL, "/v1/traces"))
+
+			cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  protocol: "+tc.protocol+"\n"))
+			if err != nil {
+				t.Fatalf("failed to load config file: %v", err)
+			}
+
+			if cfg.URL() != tc.expURL {
+				t.Errorf("expected url %q, got %q", tc.expURL, cfg.URL())
+			}
+		})
+	}
+}
+
+func TestOTelEnvInvalidDisabled(t *testing.T) {
+	t.Setenv("OTEL_SDK_DISABLED", "maybe")
+
+	buf := &syncBuffer{}
+
+	_, o, err := go11y.New(context.Background(), go11y.WithOutput(buf))
+	if err != nil {
+		t.Fatalf("expected an invalid OTEL_SDK_DISABLED to be ignored, got %v", err)
+	}
+	defer o.Close()
+
+	if o.TracingMode() == go11y.TracingModeDisabled {
+		t.Errorf("expected tracing to be enabled when OTEL_SDK_DISABLED isn't a boolean")
+	}
+
+	r := buf.find(t, "ignored an invalid configuration value")
+	if reason, _ := r["reason"].(string); r["level"] != "WARN" || !strings.Contains(reason, "OTEL_SDK_DISABLED: must be true or false, got 'maybe'") {
+		t.Errorf("expected a warning about OTEL_SDK_DISABLED, got %v", r)
+	}
+}
+
+// collector records the requests sent to it by the OTLP HTTP exporter
+type collector struct {
+	mu       sync.Mutex
+	headers  []http.Header
+	requests []*collectorTrace.ExportTraceServiceRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	req := &collectorTrace.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.headers = append(c.headers, r.Header.Clone())
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func newObserverFromEnv(t *testing.T, env map[string]string) (ctx context.Context, o *go11y.Observer) {
+	t.Helper()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	ctx, o, err = go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	return ctx, o
+}
+
+func TestOTelEnvExport(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	ctx, o := newObserverFromEnv(t, map[string]string{
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": srv.URL + "/v1/traces",
+		"OTEL_EXPORTER_OTLP_HEADERS":         "x-api-key=abc%20123",
+		"OTEL_EXPORTER_OTLP_COMPRESSION":     "none",
+		"OTEL_RESOURCE_ATTRIBUTES":           "deployment.environment=test",
+		"OTEL_SERVICE_NAME":                  "checkout",
+	})
+
+	_, span := o.Tracer("test").Start(ctx, "exported")
+	span.End()
+	o.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.requests) == 0 {
+		t.Fatalf("expected the spans to be exported")
+	}
+
+	if got := c.headers[0].Get("X-Api-Key"); got != "abc 123" {
+		t.Errorf("expected the header from OTEL_EXPORTER_OTLP_HEADERS, got %q", got)
+	}
+
+	if got := c.headers[0].Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no compression, got %q", got)
+	}
+
+	if got := c.headers[0].Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("expected a protobuf content type, got %q", got)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range c.requests[0].ResourceSpans[0].Resource.Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+
+	expected := map[string]string{"service.name": "checkout", "deployment.environment": "test"}
+	for k, v := range expected {
+		if attrs[k] != v {
+			t.Errorf("expected resource attribute %s to be %q, got %q", k, v, attrs[k])
+		}
+	}
+}
+
+func TestOTelEnvSampling(t *testing.T) {
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		expRecording bool
+	}{
+		{name: "default", env: map[string]string{}, expRecording: true},
+		{name: "sdk disabled", env: map[string]string{"OTEL_SDK_DISABLED": "true"}, expRecording: false},
+		{name: "always off", env: map[string]string{"OTEL_TRACES_SAMPLER": "always_off"}, expRecording: false},
+		{name: "ratio of zero", env: map[string]string{"OTEL_TRACES_SAMPLER": "traceidratio", "OTEL_TRACES_SAMPLER_ARG": "0"}, expRecording: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.env["OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"] = "http://127.0.0.1:1/v1/traces"
+
+			ctx, o := newObserverFromEnv(t, tc.env)
+
+			_, span := o.Tracer("test").Start(ctx, "sampled")
+			if span.IsRecording() != tc.expRecording {
+				t.Errorf("expected recording to be %t, got %t", tc.expRecording, span.IsRecording())
+			}
+			span.End()
+		})
+	}
+}
+
+func TestOTelEnvPropagators(t *testing.T) {
+	testCases := []struct {
+		name      string
+		env       map[string]string
+		expFields []string
+	}{
+		{name: "default", env: map[string]string{}, expFields: []string{"baggage", "traceparent", "tracestate"}},
+		{name: "trace context only", env: map[string]string{"OTEL_PROPAGATORS": "tracecontext"}, expFields: []string{"traceparent", "tracestate"}},
+		{name: "none", env: map[string]string{"OTEL_PROPAGATORS": "none"}, expFields: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.env["OTEL_SDK_DISABLED"] = "true"
+
+			newObserverFromEnv(t, tc.env)
+
+			fields := otel.GetTextMapPropagator().Fields()
+			slices.Sort(fields)
+
+			if !slices.Equal(fields, tc.expFields) {
+				t.Errorf("expected propagator fields %v, got %v", tc.expFields, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Processor is given every record logged by the observer, in the order the processors were added, before the record reaches
+// the handler and is recorded on the active span. It can modify the record in place, for example by adding attributes or
+// rewriting the message, and returns false to drop the record from both the log and the span.
+type Processor interface {
+	Process(ctx context.Context, r *slog.Record) (keep bool)
+}
+
+// ProcessorFunc allows an ordinary function to be used as a Processor
+type ProcessorFunc func(ctx context.Context, r *slog.Record) (keep bool)
+
+// Process calls f(ctx, r)
+func (f ProcessorFunc) Process(ctx context.Context, r *slog.Record) (keep bool) {
+	return f(ctx, r)
+}
+
+// AddProcessors appends the processors to the observer's pipeline, which is shared by the observers derived from it. It is
+// safe to call while records are being logged.
+func (o *Observer) AddProcessors(processors ...Processor) {
+	o.processors.add(processors...)
+}
+
+// pipeline holds the processors of an observer. The list is replaced rather than appended to, so records can be logged
+// with the current list while processors are added.
+type pipeline struct {
+	mu         sync.Mutex // serialises the additions
+	processors atomic.Pointer[[]Processor]
+}
+
+func newPipeline(processors []Processor) *pipeline {
+	p := &pipeline{}
+	p.processors.Store(&processors)
+
+	return p
+}
+
+// add replaces the list with a copy that has the processors appended
+func (p *pipeline) add(processors ...Processor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	list := append(append([]Processor{}, *p.processors.Load()...), processors...)
+	p.processors.Store(&list)
+}
+
+// all returns the current list of processors, which must not be modified
+func (p *pipeline) all() []Processor {
+	return *p.processors.Load()
+}
+
+// Enrich returns a Processor that adds the args (as key/value pairs) to every record, such as the build version
+func Enrich(args ...any) Processor {
+	return ProcessorFunc(func(_ context.Context, r *slog.Record) bool {
+		r.Add(args...)
+		return true
+	})
+}
+
+// EnrichFromContext returns a Processor that adds the args returned by fn to every record, such as a user ID stored in the
+// context by a middleware
+func EnrichFromContext(fn func(ctx context.Context) (args []any)) Processor {
+	return ProcessorFunc(func(ctx context.Context, r *slog.Record) bool {
+		if args := fn(ctx); len(args) != 0 {
+			r.Add(args...)
+		}
+		return true
+	})
+}
+
+// DropWhen returns a Processor that drops the records the predicate matches
+func DropWhen(predicate func(ctx context.Context, r slog.Record) (drop bool)) Processor {
+	return ProcessorFunc(func(ctx context.Context, r *slog.Record) bool {
+		return !predicate(ctx, *r)
+	})
+}
+
+// RewriteMessage returns a Processor that replaces the message of every record with the result of fn
+func RewriteMessage(fn func(msg string) (rewritten string)) Processor {
+	return ProcessorFunc(func(_ context.Context, r *slog.Record) bool {
+		r.Message = fn(r.Message)
+		return true
+	})
+}
+
+// RouteTo returns a Processor that also sends the records the predicate matches to the handler, such as an audit log.
+// The records are sent without the observer's stable args. If keep is false, the matched records are dropped from the
+// observer's own output after they have been routed.
+func RouteTo(handler slog.Handler, predicate func(ctx context.Context, r slog.Record) (route bool), keep bool) Processor {
+	return ProcessorFunc(func(ctx context.Context, r *slog.Record) bool {
+		if !predicate(ctx, *r) {
+			return true
+		}
+
+		if handler.Enabled(ctx, r.Level) {
+			_ = handler.Handle(ctx, r.Clone())
+		}
+
+		return keep
+	})
+}
@@ -0,0 +1,160 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestProcessors(t *testing.T) {
+	t.Setenv("ENV", "test")
+	t.Setenv("LOG_LEVEL", "develop")
+
+	buf := new(bytes.Buffer)
+	audit := new(bytes.Buffer)
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer func() {
+		o.Close()
+	}()
+
+	o.AddProcessors(
+		go11y.Enrich("build_version", "1.2.3"),
+		go11y.EnrichFromContext(func(ctx context.Context) []any {
+			sc := otelTrace.SpanContextFromContext(ctx)
+			if !sc.IsValid() {
+				return nil
+			}
+			return []any{"in_span", true}
+		}),
+		go11y.DropWhen(func(_ context.Context, r slog.Record) bool {
+			return strings.HasPrefix(r.Message, "healthz")
+		}),
+		go11y.RewriteMessage(strings.ToUpper),
+		go11y.RouteTo(slog.NewJSONHandler(audit, nil), func(_ context.Context, r slog.Record) bool {
+			return r.Level == go11y.LevelNotice
+		}, false),
+	)
+
+	_, o = go11y.Span(ctx, tp.Tracer("test"), "processors", go11y.SpanKindInternal)
+
+	o.Info("kept")
+	o.Debug("healthz check")
+	o.Notice("audited")
+	o.End()
+
+	t.Run("logs", func(t *testing.T) {
+		lines := withoutTracingMode(buf.String())
+		if len(lines) != 1 {
+			t.Fatalf("expected 1 log line, got %d: %s", len(lines), buf.String())
+		}
+
+		line := map[string]any{}
+		if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+			t.Fatalf("failed to parse log line: %v", err)
+		}
+
+		if line["msg"] != "KEPT" {
+			t.Errorf("expected the message to be rewritten to KEPT, got %v", line["msg"])
+		}
+
+		if line["build_version"] != "1.2.3" || line["in_span"] != true {
+			t.Errorf("expected the record to be enriched, got %v", line)
+		}
+	})
+
+	t.Run("routed", func(t *testing.T) {
+		line := map[string]any{}
+		if err := json.Unmarshal(audit.Bytes(), &line); err != nil {
+			t.Fatalf("failed to parse routed line %q: %v", audit.String(), err)
+		}
+
+		if line["msg"] != "AUDITED" {
+			t.Errorf("expected the routed message to be AUDITED, got %v", line["msg"])
+		}
+	})
+
+	t.Run("span events", func(t *testing.T) {
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		events := spans[0].Events
+		if len(events) != 1 || events[0].Name != "KEPT" {
+			t.Fatalf("expected a single KEPT event on the span, got %v", events)
+		}
+
+		found := false
+		for _, a := range spans[0].Attributes {
+			if a.Key == "build_version" && a.Value.AsString() == "1.2.3" {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("expected the enriched attribute on the span, got %v", spans[0].Attributes)
+		}
+	})
+}
+
+func TestAddProcessorsConcurrently(t *testing.T) {
+	ctx, o, err := go11y.New(context.Background(), go11y.WithOutput(&syncBuffer{}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	logger := slog.New(o.Handler())
+	_, derived := go11y.Extend(ctx, "worker", 1)
+
+	// the processors are added while the observer, a derived one and its handler are logging
+	wg := sync.WaitGroup{}
+	for range 4 {
+		wg.Go(func() {
+			for i := range 100 {
+				o.Info("logged", "i", i)
+				derived.Info("derived", "i", i)
+				logger.Info("handled", "i", i)
+			}
+		})
+	}
+
+	for i := range 100 {
+		o.AddProcessors(go11y.Enrich("processor", i))
+	}
+
+	wg.Wait()
+
+	kept := false
+	derived.AddProcessors(go11y.DropWhen(func(_ context.Context, r slog.Record) bool {
+		kept = true
+		return false
+	}))
+
+	o.Info("after")
+	if !kept {
+		t.Errorf("expected the processors added to a derived observer to be shared with the observer it came from")
+	}
+}
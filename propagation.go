@@ -0,0 +1,49 @@
+package go11y
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// The propagators that can be named in the configuration, the same as the values of OTEL_PROPAGATORS
+const (
+	PropagatorTraceContext = "tracecontext" // the W3C traceparent and tracestate headers
+	PropagatorBaggage      = "baggage"      // the W3C baggage header
+	PropagatorB3           = "b3"           // the single b3 header used by Zipkin
+	PropagatorB3Multi      = "b3multi"      // the X-B3-* headers used by Zipkin
+	PropagatorJaeger       = "jaeger"       // the uber-trace-id header used by Jaeger
+	PropagatorNone         = "none"         // no headers at all
+)
+
+// NewPropagator creates a propagator that sends the trace context and baggage in the headers of all the named
+// propagators, and receives them from any of them (the last one named wins if several headers have a trace context).
+// Empty names are ignored, and none stops anything being propagated.
+func NewPropagator(names ...string) (propagator propagation.TextMapPropagator, fault error) {
+	propagators := []propagation.TextMapPropagator{}
+
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case PropagatorJaeger:
+			propagators = append(propagators, jaeger.Jaeger{})
+		case PropagatorNone:
+			return propagation.NewCompositeTextMapPropagator(), nil
+		case "":
+		default:
+			return nil, fmt.Errorf("unsupported propagator '%s'", strings.TrimSpace(name))
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
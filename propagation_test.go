@@ -0,0 +1,159 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestPropagation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		env        string
+		expHeaders []string
+		expBaggage bool
+	}{
+		{
+			name:       "default",
+			expHeaders: []string{"Traceparent", "Baggage"},
+			expBaggage: true,
+		},
+		{
+			name:       "b3 single header",
+			env:        "b3",
+			expHeaders: []string{"B3"},
+		},
+		{
+			name:       "b3 multiple headers",
+			env:        "b3multi",
+			expHeaders: []string{"X-B3-Traceid", "X-B3-Spanid", "X-B3-Sampled"},
+		},
+		{
+			name:       "jaeger",
+			env:        "jaeger",
+			expHeaders: []string{"Uber-Trace-Id"},
+		},
+		{
+			name:       "all of them",
+			env:        "tracecontext,baggage,b3,b3multi,jaeger",
+			expHeaders: []string{"Traceparent", "Baggage", "B3", "X-B3-Traceid", "Uber-Trace-Id"},
+			expBaggage: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv("OTEL_PROPAGATORS", tc.env)
+			}
+
+			cfg, err := go11y.LoadConfig()
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(tracetest.NewInMemoryExporter()))
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+			defer o.Close()
+
+			var (
+				headers  http.Header
+				received otelTrace.SpanContext
+				tenant   string
+			)
+
+			srv := httptest.NewServer(go11y.LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers = r.Header.Clone()
+				received = otelTrace.SpanContextFromContext(r.Context())
+				tenant = baggage.FromContext(r.Context()).Member("tenant").Value()
+			})))
+			defer srv.Close()
+
+			member, _ := baggage.NewMember("tenant", "acme")
+			bag, _ := baggage.New(member)
+			ctx = baggage.ContextWithBaggage(ctx, bag)
+
+			ctx, span := o.Tracer("client").Start(ctx, "call")
+			defer span.End()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			resp, err := (&http.Client{Transport: go11y.PropagateRoundTripper(http.DefaultTransport)}).Do(req)
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			for _, h := range tc.expHeaders {
+				if headers.Get(h) == "" {
+					t.Errorf("expected the %s header to be sent, got %v", h, headers)
+				}
+			}
+
+			if received.TraceID() != span.SpanContext().TraceID() {
+				t.Errorf("expected the server span to be in trace %s, got %s", span.SpanContext().TraceID(), received.TraceID())
+			}
+
+			if received.SpanID() == span.SpanContext().SpanID() || !received.IsValid() {
+				t.Errorf("expected the server to start its own span, got %s", received.SpanID())
+			}
+
+			if tc.expBaggage != (tenant == "acme") {
+				t.Errorf("expected the baggage to be received to be %t, got the tenant %q", tc.expBaggage, tenant)
+			}
+		})
+	}
+}
+
+func TestPropagatorConfig(t *testing.T) {
+	cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  propagators: [tracecontext, b3multi]\n"))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	value := o.EffectiveConfig()["tracing.propagators"]
+	if !strings.Contains(value.Value, "traceparent") || !strings.Contains(value.Value, "x-b3-traceid") || value.Source != go11y.SourceFile {
+		t.Errorf("expected the propagators from the file, got %+v", value)
+	}
+
+	t.Setenv("OTEL_PROPAGATORS", "jaeger")
+
+	cfg, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  propagators: [b3]\n"))
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err = go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	if value := o.EffectiveConfig()["tracing.propagators"]; value.Value != "uber-trace-id" || value.Source != go11y.SourceEnv {
+		t.Errorf("expected OTEL_PROPAGATORS to take precedence over the file, got %+v", value)
+	}
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  propagators: [smoke-signal]\n"))
+	if err == nil || !strings.Contains(err.Error(), "tracing.propagators: unsupported propagator 'smoke-signal'") {
+		t.Errorf("expected an error about the unsupported propagator, got %v", err)
+	}
+}
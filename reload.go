@@ -0,0 +1,410 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// liveConfig holds the settings that can be changed while the observer is running
+type liveConfig struct {
+	level       *slog.LevelVar // the lowest of the log level and the package levels
+	packages    *packageLevelSet
+	trims       atomic.Pointer[trimLists]
+	sampler     *switchSampler
+	baggage     atomic.Pointer[[]string]
+	errorStatus atomic.Int32   // the rank of the lowest severity that sets the span status
+	handlers    *handlerSwitch // nil if the handler was provided with WithHandler
+}
+
+type trimLists struct {
+	modules []string
+	paths   []string
+}
+
+func newLiveConfig(cfg Configurator, s *settings) *liveConfig {
+	l := &liveConfig{
+		level:    &slog.LevelVar{},
+		packages: newPackageLevelSet(cfg.LogLevel(), s.packageLevels),
+		sampler:  &switchSampler{},
+	}
+
+	l.apply(cfg, s)
+
+	return l
+}
+
+// apply sets the log level, package levels, trim lists, sampler, baggage fields and error status severity from the
+// configuration and settings
+func (l *liveConfig) apply(cfg Configurator, s *settings) {
+	l.level.Set(minPackageLevel(cfg.LogLevel(), s.packageLevels))
+	l.packages.set(cfg.LogLevel(), s.packageLevels)
+	l.trims.Store(&trimLists{modules: cfg.TrimModules(), paths: cfg.TrimPaths()})
+	l.sampler.swap(effectiveSampler(s))
+	l.baggage.Store(&s.baggageFields)
+	l.errorStatus.Store(int32(severityRank(errorStatusSeverity(s))))
+}
+
+// errorStatusSeverity returns the error status severity from the settings, or the default if there isn't one
+func errorStatusSeverity(s *settings) string {
+	if s.errorStatusSeverity != "" {
+		return s.errorStatusSeverity
+	}
+
+	return DefaultErrorStatusSeverity
+}
+
+// replaceAttr modifies the log attributes with the current trim lists
+func (l *liveConfig) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	t := l.trims.Load()
+
+	return replaceAttr(t.modules, t.paths, groups, a)
+}
+
+// effectiveSampler returns the sampler from the settings, or the default sampler (which samples every trace unless the
+// parent span was not sampled) if there isn't one
+func effectiveSampler(s *settings) otelSDKTrace.Sampler {
+	if s.sampler != nil {
+		return s.sampler
+	}
+
+	return otelSDKTrace.ParentBased(otelSDKTrace.AlwaysSample())
+}
+
+// switchSampler delegates to a sampler that can be replaced while it is in use
+type switchSampler struct {
+	current atomic.Pointer[switchedSampler]
+}
+
+type switchedSampler struct {
+	sampler otelSDKTrace.Sampler
+}
+
+func (ss *switchSampler) swap(sampler otelSDKTrace.Sampler) {
+	ss.current.Store(&switchedSampler{sampler: sampler})
+}
+
+func (ss *switchSampler) ShouldSample(p otelSDKTrace.SamplingParameters) otelSDKTrace.SamplingResult {
+	return ss.current.Load().sampler.ShouldSample(p)
+}
+
+func (ss *switchSampler) Description() string {
+	return ss.current.Load().sampler.Description()
+}
+
+// Reload loads the configuration again from where it was loaded from (the configuration file, or the environment
+// variables), and applies the changes to the log level, package levels, redaction rules, sampler, log format and sinks,
+// trim lists, baggage fields and error status severity. The options passed to New still override the configuration. The changes are logged, along with any
+// changes that need a restart to take effect. If the configuration is invalid it is rejected, and the current
+// configuration stays in place.
+func (o *Observer) Reload() (fault error) {
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+
+	if err := o.reload(); err != nil {
+		o.Error("could not reload the configuration", err, SeverityMedium)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Observer) reload() (fault error) {
+	var (
+		cfg *Configuration
+		err error
+	)
+
+	switch {
+	case o.source == nil:
+		return errors.New("the configuration was not loaded from a file or the environment variables")
+	case o.source.path != "":
+		cfg, err = LoadConfigFile(o.source.path)
+	default:
+		cfg, err = LoadConfig()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	s := &settings{}
+	for _, opt := range append(cfg.options(), o.opts...) {
+		opt(s)
+	}
+
+	var newCfg Configurator = cfg
+	if s.level != nil {
+		newCfg = levelConfig{Configurator: cfg, level: *s.level}
+	}
+
+	oldValues, newValues := reloadableValues(o.cfg, o.settings), reloadableValues(newCfg, s)
+	restart := changedKeys(restartValues(o.cfg, o.settings), restartValues(newCfg, s))
+
+	changed := changedKeys(oldValues, newValues)
+	if len(changed) == 0 && len(restart) == 0 {
+		o.Debug("configuration reloaded without changes")
+		return nil
+	}
+
+	// everything that can fail is done before anything is applied, so an invalid configuration changes nothing
+	rules := DefaultRedactionRules()
+	if s.redaction != nil {
+		rules = s.redaction
+	}
+
+	if _, err := compileRedactionRules(rules); err != nil {
+		return err
+	}
+
+	var (
+		sinks   slog.Handler
+		closers []io.Closer
+	)
+
+	rebuild := o.live.handlers != nil && (slices.Contains(changed, "log.format") || slices.Contains(changed, "log.sinks"))
+	if rebuild {
+		sinks, _, closers, err = sinksHandler(newCfg, s, defaultOptions(o.live))
+		if err != nil {
+			return err
+		}
+	}
+
+	// the changes are logged before they are applied, so they are still logged if the new level is higher
+	if len(changed) != 0 {
+		args := make([]any, 0, len(changed)*2)
+		for _, k := range changed {
+			args = append(args, k, map[string]string{"old": oldValues[k], "new": newValues[k]})
+		}
+
+		o.Info("configuration reloaded", args...)
+	}
+
+	if len(restart) != 0 {
+		o.Warning("configuration changes need a restart to take effect", "settings", restart)
+	}
+
+	_ = o.redactor.SetRules(rules...)
+	o.live.apply(newCfg, s)
+
+	if rebuild {
+		// the old sinks are only closed once the records being written to them have been written
+		o.live.handlers.swap(sinks).retire()
+
+		for _, c := range o.closers {
+			_ = c.Close()
+		}
+		o.closers = closers
+	}
+
+	o.cfg = newCfg
+	o.source = cfg
+	o.settings = s
+
+	return nil
+}
+
+// reloadableValues describes the settings that Reload applies, keyed by their path in a configuration file
+func reloadableValues(cfg Configurator, s *settings) map[string]string {
+	levels := make([]string, 0, len(s.packageLevels))
+	for pkg, level := range s.packageLevels {
+		levels = append(levels, pkg+"="+LevelName(level))
+	}
+	sort.Strings(levels)
+
+	sinks := make([]string, 0, len(s.sinks))
+	for _, sink := range s.sinks {
+		sinks = append(sinks, fmt.Sprintf("%s(format=%s path=%s network=%s address=%s facility=%s)", sink.Type, sink.Format, sink.Path, sink.Network, sink.Address, sink.Facility))
+	}
+
+	rules := s.redaction
+	if rules == nil {
+		rules = DefaultRedactionRules()
+	}
+
+	redaction := make([]string, 0, len(rules))
+	for _, r := range rules {
+		redaction = append(redaction, fmt.Sprintf("key=%s value=%s action=%s keep=%d", r.Key, r.Value, r.Action, r.Keep))
+	}
+
+	format := s.format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	return map[string]string{
+		"log.level":                     LevelName(cfg.LogLevel()),
+		"log.levels":                    strings.Join(levels, ", "),
+		"log.format":                    format,
+		"log.sinks":                     strings.Join(sinks, ", "),
+		"log.trim_modules":              strings.Join(cfg.TrimModules(), ", "),
+		"log.trim_paths":                strings.Join(cfg.TrimPaths(), ", "),
+		"redaction.rules":               strings.Join(redaction, "; "),
+		"tracing.sampler":               effectiveSampler(s).Description(),
+		"tracing.baggage_fields":        strings.Join(s.baggageFields, ", "),
+		"tracing.error_status_severity": errorStatusSeverity(s),
+	}
+}
+
+// restartValues describes the settings that need a restart to take effect, keyed by their path in a configuration file.
+// The values are only compared, never logged, as some of them hold credentials.
+func restartValues(cfg Configurator, s *settings) map[string]string {
+	headers := make([]string, 0, len(s.traceHeaders))
+	for k, v := range s.traceHeaders {
+		headers = append(headers, k+"="+v)
+	}
+	sort.Strings(headers)
+
+	attrs := make([]string, 0, len(s.resourceAttrs))
+	for k, v := range s.resourceAttrs {
+		attrs = append(attrs, k+"="+v)
+	}
+	sort.Strings(attrs)
+
+	propagators := propagator(s).Fields()
+	sort.Strings(propagators)
+
+	tailSampling := ""
+	if s.tailSampling != nil {
+		tailSampling = s.tailSampling.String()
+	}
+
+	id := configIdentity(cfg)
+
+	return map[string]string{
+		"service.name":                cfg.ServiceName(),
+		"service.version":             id.Version,
+		"service.namespace":           id.Namespace,
+		"service.instance_id":         id.InstanceID,
+		"environment":                 id.Environment,
+		"tracing.url":                 cfg.URL(),
+		"tracing.headers":             strings.Join(headers, ","),
+		"tracing.exporter":            s.traceExporter,
+		"tracing.file.path":           s.traceFile.Path,
+		"tracing.file.max_size":       strconv.FormatInt(s.traceFile.MaxSize, 10),
+		"tracing.file.max_backups":    strconv.Itoa(s.traceFile.MaxBackups),
+		"tracing.protocol":            s.traceProtocol,
+		"tracing.timeout":             s.traceTimeout.String(),
+		"tracing.compression":         s.compression,
+		"tracing.tls.ca_file":         s.traceTLS.CAFile,
+		"tracing.tls.cert_file":       s.traceTLS.CertFile,
+		"tracing.tls.key_file":        s.traceTLS.KeyFile,
+		"tracing.tls.skip_verify":     fmt.Sprintf("%t", s.traceTLS.SkipVerify),
+		"tracing.resource_attributes": strings.Join(attrs, ","),
+		"tracing.propagators":         strings.Join(propagators, ","),
+		"tracing.disabled":            fmt.Sprintf("%t", s.tracingDisabled),
+		"tracing.tail_sampling":       tailSampling,
+		"db.constr":                   cfg.DBConStr(),
+		"db.migrate":                  fmt.Sprintf("%t", !s.skipMigrations),
+	}
+}
+
+// changedKeys returns the sorted keys with different values in old and new
+func changedKeys(old, new map[string]string) []string {
+	changed := []string{}
+	for k, v := range new {
+		if old[k] != v {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+// WatchConfig reloads the configuration with Reload when the process receives SIGHUP and, if the configuration was
+// loaded from a file, when the contents of the file change (checked every interval). It stops watching when ctx is
+// done, when stop is called, or when the observer is closed.
+func (o *Observer) WatchConfig(ctx context.Context, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	path := ""
+	if o.source != nil {
+		path = o.source.path
+	}
+
+	last := fileHash(path)
+
+	go func() {
+		defer close(stopped)
+		defer signal.Stop(hup)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-hup:
+				_ = o.Reload()
+				last = fileHash(path)
+			case <-ticker.C:
+				if path == "" {
+					continue
+				}
+
+				if h := fileHash(path); h != nil && !bytes.Equal(h, last) {
+					last = h
+					_ = o.Reload()
+				}
+			}
+		}
+	}()
+
+	var once atomic.Bool
+
+	stop = func() {
+		if once.CompareAndSwap(false, true) {
+			close(done)
+			<-stopped
+		}
+	}
+
+	o.reloadMu.Lock()
+	o.stopWatches = append(o.stopWatches, stop)
+	o.reloadMu.Unlock()
+
+	return stop
+}
+
+// fileHash returns the hash of the file's contents, or nil if it can't be read
+func fileHash(path string) []byte {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	h := sha256.Sum256(data)
+
+	return h[:]
+}
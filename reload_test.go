@@ -0,0 +1,295 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// syncBuffer is a buffer that can be written to by the config watcher while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// records returns the log records written so far
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("could not unmarshal log line: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// find returns the first record with the message
+func (b *syncBuffer) find(t *testing.T, msg string) map[string]any {
+	t.Helper()
+
+	for _, r := range b.records(t) {
+		if r["msg"] == msg {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func newReloadObserver(t *testing.T, path string, buf *syncBuffer, opts ...go11y.Option) *go11y.Observer {
+	t.Helper()
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err := go11y.New(context.Background(), append([]go11y.Option{
+		go11y.WithConfig(cfg),
+		go11y.WithOutput(buf),
+		go11y.WithTraceExporter(tracetest.NewInMemoryExporter()),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	t.Cleanup(o.Close)
+
+	return o
+}
+
+func TestReload(t *testing.T) {
+	buf := &syncBuffer{}
+	path := writeConfigFile(t, "go11y.yaml", "log:\n  level: info\n")
+	o := newReloadObserver(t, path, buf)
+
+	o.Debug("before the reload")
+
+	if err := os.WriteFile(path, []byte("log:\n  level: debug\ntracing:\n  url: http://collector:4318/v1/traces\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	if err := o.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	o.Debug("after the reload")
+
+	if buf.find(t, "before the reload") != nil {
+		t.Errorf("expected the debug record before the reload to be filtered out")
+	}
+
+	if buf.find(t, "after the reload") == nil {
+		t.Errorf("expected the debug record after the reload to be logged")
+	}
+
+	reloaded := buf.find(t, "configuration reloaded")
+	if reloaded == nil {
+		t.Fatalf("expected the reload to be logged")
+	}
+
+	diff, _ := reloaded["log.level"].(map[string]any)
+	if diff["old"] != "INFO" || diff["new"] != "DEBUG" {
+		t.Errorf("expected the level change in the diff, got %v", reloaded["log.level"])
+	}
+
+	restart := buf.find(t, "configuration changes need a restart to take effect")
+	if restart == nil {
+		t.Fatalf("expected the changes that need a restart to be logged")
+	}
+
+	if settings, _ := restart["settings"].([]any); len(settings) != 1 || settings[0] != "tracing.url" {
+		t.Errorf("expected tracing.url to need a restart, got %v", restart["settings"])
+	}
+}
+
+func TestReloadSinksWhileLogging(t *testing.T) {
+	dir := t.TempDir()
+	sinks := func(name string) string {
+		return "log:\n  level: info\n  sinks:\n    - type: file\n      format: json\n      path: " + filepath.Join(dir, name) + "\n"
+	}
+
+	path := writeConfigFile(t, "go11y.yaml", sinks("0.log"))
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	_, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithTraceExporter(tracetest.NewInMemoryExporter()))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	const reloads = 20
+
+	// the records are logged until the reloads are done
+	stop := make(chan struct{})
+	records := atomic.Int64{}
+
+	wg := sync.WaitGroup{}
+	for w := range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					o.Info("while reloading", "worker", w)
+					records.Add(1)
+				}
+			}
+		})
+	}
+
+	// the sinks are replaced while the records are being written, and the old ones closed
+	for i := 1; i <= reloads; i++ {
+		if err := os.WriteFile(path, []byte(sinks(strconv.Itoa(i)+".log")), 0o600); err != nil {
+			t.Fatalf("could not write config file: %v", err)
+		}
+
+		if err := o.Reload(); err != nil {
+			t.Fatalf("failed to reload: %v", err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	o.Close()
+
+	logged := 0
+	for i := 0; i <= reloads; i++ {
+		data, err := os.ReadFile(filepath.Join(dir, strconv.Itoa(i)+".log"))
+		if err != nil {
+			t.Fatalf("could not read log file: %v", err)
+		}
+
+		logged += strings.Count(string(data), `"msg":"while reloading"`)
+	}
+
+	if int64(logged) != records.Load() {
+		t.Errorf("expected the %d records to be written to the sinks, got %d", records.Load(), logged)
+	}
+}
+
+func TestReloadWithLevel(t *testing.T) {
+	buf := &syncBuffer{}
+	path := writeConfigFile(t, "go11y.yaml", "log:\n  level: info\n")
+	o := newReloadObserver(t, path, buf, go11y.WithLevel(go11y.LevelDebug))
+
+	if err := os.WriteFile(path, []byte("log:\n  level: error\ntracing:\n  url: http://collector:4318/v1/traces\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	if err := o.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	o.Debug("after the reload")
+
+	if buf.find(t, "after the reload") == nil {
+		t.Errorf("expected the level passed to New to override the reloaded level")
+	}
+
+	if buf.find(t, "configuration changes need a restart to take effect") == nil {
+		t.Errorf("expected the reloaded changes that need a restart to be logged")
+	}
+}
+
+func TestReloadInvalid(t *testing.T) {
+	buf := &syncBuffer{}
+	path := writeConfigFile(t, "go11y.yaml", "log:\n  level: info\n")
+	o := newReloadObserver(t, path, buf)
+
+	if err := os.WriteFile(path, []byte("log:\n  level: wraning\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	if err := o.Reload(); err == nil {
+		t.Fatalf("expected an invalid config to be rejected")
+	}
+
+	o.Debug("filtered out")
+	o.Info("logged")
+
+	if buf.find(t, "could not reload the configuration") == nil {
+		t.Errorf("expected the rejected config to be logged")
+	}
+
+	if buf.find(t, "filtered out") != nil || buf.find(t, "logged") == nil {
+		t.Errorf("expected the old level to stay in place, got %v", buf.records(t))
+	}
+}
+
+func TestReloadNotLoaded(t *testing.T) {
+	_, o, err := go11y.New(context.Background(),
+		go11y.WithConfig(go11y.CreateConfig(go11y.LevelInfo, "", "", "reload", nil, nil)),
+		go11y.WithOutput(&syncBuffer{}),
+		go11y.WithTraceExporter(tracetest.NewInMemoryExporter()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+	defer o.Close()
+
+	if err := o.Reload(); err == nil {
+		t.Errorf("expected an error reloading a config that wasn't loaded from a file or the environment")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	buf := &syncBuffer{}
+	path := writeConfigFile(t, "go11y.yaml", "log:\n  level: info\n")
+	o := newReloadObserver(t, path, buf)
+
+	stop := o.WatchConfig(context.Background(), 10*time.Millisecond)
+	defer stop()
+
+	if err := os.WriteFile(path, []byte("log:\n  level: warn\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for buf.find(t, "configuration reloaded") == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the config file change to be reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	o.Info("filtered out")
+
+	if buf.find(t, "filtered out") != nil {
+		t.Errorf("expected the new level to be applied")
+	}
+}
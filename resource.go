@@ -0,0 +1,209 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// The environment variables the Kubernetes detector reads, which can be set from the pod's fields with the downward API
+const (
+	K8sPodNameEnv       = "K8S_POD_NAME"
+	K8sPodUIDEnv        = "K8S_POD_UID"
+	K8sNamespaceEnv     = "K8S_NAMESPACE_NAME"
+	K8sNodeNameEnv      = "K8S_NODE_NAME"
+	K8sContainerNameEnv = "K8S_CONTAINER_NAME"
+)
+
+// DefaultResourceDetectors returns the detectors that describe where the service is running: the host name and OS, the
+// process ID, executable and Go runtime version, the container ID, and the Kubernetes pod, namespace and node
+func DefaultResourceDetectors() []otelResource.Detector {
+	return []otelResource.Detector{
+		sdkDetector{otelResource.WithHost(), otelResource.WithOS()},
+		sdkDetector{
+			otelResource.WithProcessPID(),
+			otelResource.WithProcessExecutableName(),
+			otelResource.WithProcessExecutablePath(),
+			otelResource.WithProcessRuntimeName(),
+			otelResource.WithProcessRuntimeVersion(),
+		},
+		ContainerDetector{},
+		KubernetesDetector{},
+	}
+}
+
+// WithResourceDetectors describes where the service is running with the detectors instead of DefaultResourceDetectors,
+// or doesn't detect anything if there aren't any
+func WithResourceDetectors(detectors ...otelResource.Detector) Option {
+	return func(s *settings) {
+		s.detectors = append([]otelResource.Detector{}, detectors...)
+	}
+}
+
+// sdkDetector detects the attributes with the resource options of the OpenTelemetry SDK
+type sdkDetector []otelResource.Option
+
+func (d sdkDetector) Detect(ctx context.Context) (res *otelResource.Resource, fault error) {
+	return otelResource.New(ctx, d...)
+}
+
+// ContainerDetector detects the ID of the container the process is running in from its cgroup (for cgroup v1) or its
+// mounts (for cgroup v2)
+type ContainerDetector struct {
+	CgroupPath    string // /proc/self/cgroup if it is empty
+	MountInfoPath string // /proc/self/mountinfo if it is empty
+}
+
+var (
+	cgroupContainerID    = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+	mountInfoContainerID = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+// Detect returns the container ID, or an empty resource if the process isn't running in a container
+func (d ContainerDetector) Detect(ctx context.Context) (res *otelResource.Resource, fault error) {
+	cgroupPath, mountInfoPath := d.CgroupPath, d.MountInfoPath
+	if cgroupPath == "" {
+		cgroupPath = "/proc/self/cgroup"
+	}
+
+	if mountInfoPath == "" {
+		mountInfoPath = "/proc/self/mountinfo"
+	}
+
+	id := ""
+
+	if data, err := os.ReadFile(cgroupPath); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := cgroupContainerID.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				id = m[1]
+				break
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(mountInfoPath); err == nil && id == "" {
+		if m := mountInfoContainerID.FindStringSubmatch(string(data)); m != nil {
+			id = m[1]
+		}
+	}
+
+	if id == "" {
+		return otelResource.Empty(), nil
+	}
+
+	return otelResource.NewWithAttributes(otelSemConv.SchemaURL, otelSemConv.ContainerID(id)), nil
+}
+
+// KubernetesDetector detects the pod, namespace, node and container from the environment variables set with the
+// downward API, such as K8S_POD_NAME from metadata.name
+type KubernetesDetector struct{}
+
+// Detect returns the attributes of the environment variables that are set
+func (KubernetesDetector) Detect(ctx context.Context) (res *otelResource.Resource, fault error) {
+	attrs := []otelAttribute.KeyValue{}
+
+	for env, key := range map[string]otelAttribute.Key{
+		K8sPodNameEnv:       otelSemConv.K8SPodNameKey,
+		K8sPodUIDEnv:        otelSemConv.K8SPodUIDKey,
+		K8sNamespaceEnv:     otelSemConv.K8SNamespaceNameKey,
+		K8sNodeNameEnv:      otelSemConv.K8SNodeNameKey,
+		K8sContainerNameEnv: otelSemConv.K8SContainerNameKey,
+	} {
+		if v := os.Getenv(env); v != "" {
+			attrs = append(attrs, key.String(v))
+		}
+	}
+
+	if len(attrs) == 0 {
+		return otelResource.Empty(), nil
+	}
+
+	return otelResource.NewWithAttributes(otelSemConv.SchemaURL, attrs...), nil
+}
+
+// serviceResource returns the resource from the settings (or the service name and resource attributes from the
+// configuration if there isn't one), merged over the attributes the detectors found. The identity from the
+// configuration takes precedence over OTEL_RESOURCE_ATTRIBUTES, which takes precedence over the detected attributes.
+func serviceResource(ctx context.Context, cfg Configurator, s *settings) *otelResource.Resource {
+	res := s.resource
+	if res == nil {
+		serviceName := cfg.ServiceName()
+		if serviceName == "" {
+			serviceName = s.resourceAttrs[string(otelSemConv.ServiceNameKey)]
+		}
+
+		id := configIdentity(cfg)
+		identity := map[otelAttribute.Key]string{
+			otelSemConv.ServiceNameKey:           serviceName,
+			otelSemConv.DeploymentEnvironmentKey: id.Environment,
+			otelSemConv.ServiceVersionKey:        id.Version,
+			otelSemConv.ServiceNamespaceKey:      id.Namespace,
+			otelSemConv.ServiceInstanceIDKey:     id.InstanceID,
+		}
+
+		attrs := make([]otelAttribute.KeyValue, 0, len(s.resourceAttrs)+len(identity))
+		for k, v := range s.resourceAttrs {
+			if _, ok := identity[otelAttribute.Key(k)]; !ok {
+				attrs = append(attrs, otelAttribute.String(k, v))
+			}
+		}
+
+		for k, v := range identity {
+			if v != "" || k == otelSemConv.ServiceNameKey {
+				attrs = append(attrs, k.String(v))
+			}
+		}
+
+		res = otelResource.NewWithAttributes(otelSemConv.SchemaURL, attrs...)
+	}
+
+	detectors := s.detectors
+	if detectors == nil {
+		detectors = DefaultResourceDetectors()
+	}
+
+	// a detector that fails leaves its attributes out, rather than stopping the observer from starting
+	detected, _ := otelResource.New(ctx, otelResource.WithDetectors(detectors...))
+
+	// the detected attributes are merged without their schema, so they can't conflict with the schema of the resource
+	merged, err := otelResource.Merge(otelResource.NewSchemaless(detected.Attributes()...), res)
+	if err != nil {
+		return res
+	}
+
+	return merged
+}
+
+// resourceLogAttrs returns the log fields for the attributes of the resource that identify the deployment the service is
+// running in. The host name and process are only on the resource, as they would add the same noise to every record.
+func resourceLogAttrs(res *otelResource.Resource) []slog.Attr {
+	attrs := []slog.Attr{}
+
+	for _, f := range []struct {
+		field string
+		key   otelAttribute.Key
+	}{
+		{FieldContainerID, otelSemConv.ContainerIDKey},
+		{FieldK8sNamespace, otelSemConv.K8SNamespaceNameKey},
+		{FieldK8sPod, otelSemConv.K8SPodNameKey},
+		{FieldK8sNode, otelSemConv.K8SNodeNameKey},
+	} {
+		if v, ok := res.Set().Value(f.key); ok && v.Emit() != "" {
+			attrs = append(attrs, slog.String(f.field, v.Emit()))
+		}
+	}
+
+	return attrs
+}
+
+// Resource returns the resource that describes the service and where it is running, which is used for the spans. It
+// can be passed to a meter provider (with metric.WithResource) so the metrics are described in the same way.
+func (o *Observer) Resource() *otelResource.Resource {
+	return o.resource
+}
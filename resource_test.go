@@ -0,0 +1,167 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/attribute"
+	otelResource "go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestContainerDetector(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+
+	testCases := []struct {
+		name      string
+		cgroup    string
+		mountInfo string
+		expected  string
+	}{
+		{
+			name:     "docker cgroup v1",
+			cgroup:   "12:memory:/docker/" + id + "\n11:cpu:/docker/" + id + "\n",
+			expected: id,
+		},
+		{
+			name:     "kubernetes cgroup v1",
+			cgroup:   "0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-" + id + ".scope\n",
+			expected: id,
+		},
+		{
+			name:      "cgroup v2",
+			cgroup:    "0::/\n",
+			mountInfo: "612 589 254:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n",
+			expected:  id,
+		},
+		{
+			name:      "not in a container",
+			cgroup:    "0::/user.slice/user-1000.slice/session-2.scope\n",
+			mountInfo: "22 1 254:1 / / rw,relatime - ext4 /dev/vda1 rw\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := go11y.ContainerDetector{CgroupPath: filepath.Join(dir, "cgroup"), MountInfoPath: filepath.Join(dir, "mountinfo")}
+
+			for path, contents := range map[string]string{d.CgroupPath: tc.cgroup, d.MountInfoPath: tc.mountInfo} {
+				if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+					t.Fatalf("could not write %s: %v", path, err)
+				}
+			}
+
+			res, err := d.Detect(context.Background())
+			if err != nil {
+				t.Fatalf("failed to detect: %v", err)
+			}
+
+			v, _ := res.Set().Value(otelSemConv.ContainerIDKey)
+			if v.AsString() != tc.expected {
+				t.Errorf("expected the container ID to be %q, got %q", tc.expected, v.AsString())
+			}
+		})
+	}
+}
+
+func TestResourceDetection(t *testing.T) {
+	t.Setenv(go11y.K8sPodNameEnv, "checkout-7d9f")
+	t.Setenv(go11y.K8sNamespaceEnv, "shop")
+	t.Setenv(go11y.K8sNodeNameEnv, "node-1")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "k8s.node.name=node-2,team=payments")
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(buf), go11y.WithTraceExporter(keepSpans{exporter}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	_, span := o.Tracer("test").Start(ctx, "detected")
+	span.End()
+	o.Info("detected")
+	o.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	hostname, _ := os.Hostname()
+
+	expected := map[attribute.Key]string{
+		otelSemConv.HostNameKey:              hostname,
+		otelSemConv.ProcessRuntimeVersionKey: runtime.Version(),
+		otelSemConv.K8SPodNameKey:            "checkout-7d9f",
+		otelSemConv.K8SNamespaceNameKey:      "shop",
+		otelSemConv.K8SNodeNameKey:           "node-2", // OTEL_RESOURCE_ATTRIBUTES takes precedence over the detectors
+		"team":                               "payments",
+	}
+
+	for _, res := range []*otelResource.Resource{spans[0].Resource, o.Resource()} {
+		for k, v := range expected {
+			if actual, _ := res.Set().Value(k); actual.Emit() != v {
+				t.Errorf("expected %s to be %q, got %q", k, v, actual.Emit())
+			}
+		}
+
+		if pid, ok := res.Set().Value(otelSemConv.ProcessPIDKey); !ok || pid.AsInt64() != int64(os.Getpid()) {
+			t.Errorf("expected the process ID to be %d, got %v", os.Getpid(), pid.Emit())
+		}
+	}
+
+	line := strings.SplitN(buf.String(), "\n", 2)[0]
+	for _, field := range []string{`"k8s_namespace":"shop"`, `"k8s_pod":"checkout-7d9f"`, `"k8s_node":"node-2"`} {
+		if !strings.Contains(line, field) {
+			t.Errorf("expected the log record to contain %s, got %s", field, line)
+		}
+	}
+}
+
+func TestWithResourceDetectors(t *testing.T) {
+	t.Setenv(go11y.K8sPodNameEnv, "checkout-7d9f")
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, o, err := go11y.New(context.Background(),
+		go11y.WithOutput(&bytes.Buffer{}),
+		go11y.WithTraceExporter(keepSpans{exporter}),
+		go11y.WithResource(otelResource.NewSchemaless(otelSemConv.ServiceNameKey.String("checkout"))),
+		go11y.WithResourceDetectors(go11y.KubernetesDetector{}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	_, span := o.Tracer("test").Start(ctx, "detected")
+	span.End()
+	o.Close()
+
+	res := exporter.GetSpans()[0].Resource
+
+	if pod, _ := res.Set().Value(otelSemConv.K8SPodNameKey); pod.AsString() != "checkout-7d9f" {
+		t.Errorf("expected the pod from the Kubernetes detector, got %q", pod.AsString())
+	}
+
+	if name, _ := res.Set().Value(otelSemConv.ServiceNameKey); name.AsString() != "checkout" {
+		t.Errorf("expected the service name from the resource, got %q", name.AsString())
+	}
+
+	if _, ok := res.Set().Value(otelSemConv.HostNameKey); ok {
+		t.Errorf("expected only the Kubernetes detector to be used")
+	}
+}
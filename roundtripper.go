@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
 // AddTracingToHTTPClient wraps a HTTP client's transporter with OpenTelemetry instrumentation
@@ -88,21 +89,24 @@ func LogRoundTripper(next http.RoundTripper) http.RoundTripper {
 		}
 
 		requestArgs := []any{
-			FieldRequestHeaders, RedactHeaders(r.Header),
+			FieldRequestHeaders, o.redactor.RedactHeaders(r.Header),
 			FieldRequestMethod, r.Method,
 			FieldRequestURL, r.URL.String(),
 			FieldRequestBody, reqBody,
 		}
 
-		o.log(ctx, 8, LevelInfo, "outbound call - request", requestArgs...)
+		_, _ = o.log(ctx, 8, LevelInfo, "outbound call - request", requestArgs...)
 		start := time.Now()
 
 		// Send the actual request
 		resp, err := next.RoundTrip(r)
 		if err != nil {
+			recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, 0, err)
 			return nil, err
 		}
 
+		recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, resp.StatusCode, nil)
+
 		respBody := []byte{}
 		// read the response body, use it to log the response body, then build a new response to return
 		if resp.Body != nil {
@@ -123,10 +127,10 @@ func LogRoundTripper(next http.RoundTripper) http.RoundTripper {
 		responseArgs := []any{
 			FieldCallDuration, duration,
 			FieldStatusCode, resp.StatusCode,
-			FieldResponseHeaders, RedactHeaders(resp.Header),
+			FieldResponseHeaders, o.redactor.RedactHeaders(resp.Header),
 			FieldResponseBody, string(respBody),
 		}
-		o.log(ctx, 8, LevelInfo, "outbound call - response", responseArgs...)
+		_, _ = o.log(ctx, 8, LevelInfo, "outbound call - response", responseArgs...)
 		return resp, nil
 	})
 }
@@ -153,9 +157,12 @@ func DBStoreRoundTripper(next http.RoundTripper) http.RoundTripper {
 
 		resp, err := next.RoundTrip(r)
 		if err != nil {
+			recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, 0, err)
 			return nil, err
 		}
 
+		recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, resp.StatusCode, nil)
+
 		respBody := []byte{}
 		// read the response body, use it to log the response body, then build a new response to return
 		if resp.Body != nil {
@@ -181,16 +188,10 @@ func DBStoreRoundTripper(next http.RoundTripper) http.RoundTripper {
 	})
 }
 
+// RedactHeaders returns a copy of the headers with credentials (such as the Authorization and Cookie headers), tokens and
+// other sensitive values redacted by the default redaction rules
 func RedactHeaders(headers http.Header) http.Header {
-	redactedHeaders := make(http.Header)
-	for key, values := range headers {
-		if key == "Authorization" || key == "Cookie" {
-			redactedHeaders[key] = []string{"REDACTED"}
-		} else {
-			redactedHeaders[key] = values
-		}
-	}
-	return redactedHeaders
+	return defaultRedactor.RedactHeaders(headers)
 }
 
 func PropagateRoundTripper(next http.RoundTripper) http.RoundTripper {
@@ -199,6 +200,14 @@ func PropagateRoundTripper(next http.RoundTripper) http.RoundTripper {
 
 		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
 
-		return next.RoundTrip(r)
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, 0, err)
+			return nil, err
+		}
+
+		recordHTTPStatus(otelTrace.SpanFromContext(ctx), otelTrace.SpanKindClient, resp.StatusCode, nil)
+
+		return resp, nil
 	})
 }
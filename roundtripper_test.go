@@ -3,12 +3,18 @@ package go11y_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/jsnfwlr/go11y"
 	"github.com/jsnfwlr/go11y/testingContainers"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	otelTrace "go.opentelemetry.io/otel/trace"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/testcontainers/testcontainers-go"
@@ -198,3 +204,145 @@ func TestRoundtripperPropagator(t *testing.T) {
 		_ = resp.Body.Close()
 	}()
 }
+
+func TestRoundtripperStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	testCases := []struct {
+		name         string
+		url          string
+		roundTripper func(http.RoundTripper) http.RoundTripper
+		expStatus    int64
+		expErrorType string
+	}{
+		{name: "propagate ok", url: srv.URL + "/ok", roundTripper: go11y.PropagateRoundTripper, expStatus: http.StatusOK},
+		{name: "propagate not found", url: srv.URL + "/missing", roundTripper: go11y.PropagateRoundTripper, expStatus: http.StatusNotFound, expErrorType: "404"},
+		{name: "log server error", url: srv.URL + "/broken", roundTripper: go11y.LogRoundTripper, expStatus: http.StatusInternalServerError, expErrorType: "500"},
+		{name: "log connection refused", url: closed.URL, roundTripper: go11y.LogRoundTripper, expErrorType: "*net.OpError"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+
+			ctx, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+
+			ctx, span := o.Tracer("test").Start(ctx, "call", otelTrace.WithSpanKind(go11y.SpanKindClient))
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			resp, err := (&http.Client{Transport: tc.roundTripper(http.DefaultTransport)}).Do(req)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+
+			span.End()
+			o.Close()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			var (
+				status    int64
+				errorType string
+			)
+			for _, kv := range spans[0].Attributes {
+				switch kv.Key {
+				case otelSemConv.HTTPResponseStatusCodeKey:
+					status = kv.Value.AsInt64()
+				case otelSemConv.ErrorTypeKey:
+					errorType = kv.Value.AsString()
+				}
+			}
+
+			if status != tc.expStatus || errorType != tc.expErrorType {
+				t.Errorf("expected the status code %d and error type %q, got %d and %q", tc.expStatus, tc.expErrorType, status, errorType)
+			}
+
+			if isError := spans[0].Status.Code == codes.Error; isError != (tc.expErrorType != "") {
+				t.Errorf("expected the span status to be an error %t, got %+v", tc.expErrorType != "", spans[0].Status)
+			}
+		})
+	}
+}
+
+func TestRoundtripperStatusInsideLogRequest(t *testing.T) {
+	downstream := httptest.NewServer(http.NotFoundHandler())
+	defer downstream.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	_, o, err := go11y.New(context.Background(), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	// the handler calls a downstream service that fails, with the server's span in the context, and handles it
+	srv := httptest.NewServer(go11y.LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+		if err != nil {
+			t.Errorf("failed to create request: %v", err)
+			return
+		}
+
+		client := &http.Client{Transport: go11y.LogRoundTripper(go11y.PropagateRoundTripper(http.DefaultTransport))}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("failed to send request: %v", err)
+			return
+		}
+		_ = resp.Body.Close()
+
+		_, _ = w.Write([]byte("ok"))
+	})))
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	srv.Close()
+	o.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	var (
+		status    int64
+		errorType string
+	)
+	for _, kv := range spans[0].Attributes {
+		switch kv.Key {
+		case otelSemConv.HTTPResponseStatusCodeKey:
+			status = kv.Value.AsInt64()
+		case otelSemConv.ErrorTypeKey:
+			errorType = kv.Value.AsString()
+		}
+	}
+
+	if status != http.StatusOK || errorType != "" || spans[0].Status.Code == codes.Error {
+		t.Errorf("expected the server's span to keep its own status, got %d, %q and %+v", status, errorType, spans[0].Status)
+	}
+}
@@ -0,0 +1,216 @@
+package go11y
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// The samplers that can be named in the configuration, the same as the values of OTEL_TRACES_SAMPLER
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// NewSampler creates the named sampler, using the ratio for the trace ID ratio based samplers
+func NewSampler(name string, ratio float64) (sampler otelSDKTrace.Sampler, fault error) {
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("the ratio must be between 0 and 1, got %v", ratio)
+	}
+
+	switch name {
+	case SamplerAlwaysOn:
+		return otelSDKTrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return otelSDKTrace.NeverSample(), nil
+	case SamplerTraceIDRatio:
+		return otelSDKTrace.TraceIDRatioBased(ratio), nil
+	case SamplerParentBasedAlwaysOn:
+		return otelSDKTrace.ParentBased(otelSDKTrace.AlwaysSample()), nil
+	case SamplerParentBasedAlwaysOff:
+		return otelSDKTrace.ParentBased(otelSDKTrace.NeverSample()), nil
+	case SamplerParentBasedTraceIDRatio:
+		return otelSDKTrace.ParentBased(otelSDKTrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler '%s'", name)
+	}
+}
+
+// SamplingRule samples a fraction of the traces whose root span matches all of the rule's non-empty fields. The
+// fields are globs, as used by path.Match, so "/users/*" matches "/users/42".
+type SamplingRule struct {
+	Name       string            `yaml:"name" json:"name"`             // matched against the name of the span
+	Route      string            `yaml:"route" json:"route"`           // matched against the http.route attribute, or url.path (or http.target) if it isn't set
+	Method     string            `yaml:"method" json:"method"`         // matched against the http.request.method (or http.method) attribute
+	Attributes map[string]string `yaml:"attributes" json:"attributes"` // matched against the attributes with the same keys
+	Ratio      float64           `yaml:"ratio" json:"ratio"`           // the fraction of the matching traces that are sampled, between 0 and 1
+}
+
+func (r SamplingRule) validate() (fault error) {
+	if r.Ratio < 0 || r.Ratio > 1 {
+		return fmt.Errorf("the ratio must be between 0 and 1, got %v", r.Ratio)
+	}
+
+	patterns := []string{r.Name, r.Route, r.Method}
+	for _, v := range r.Attributes {
+		patterns = append(patterns, v)
+	}
+
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// matches returns true if the span matches all of the rule's patterns
+func (r SamplingRule) matches(name string, attrs map[otelAttribute.Key]string) bool {
+	match := func(pattern, value string) bool {
+		if pattern == "" {
+			return true
+		}
+
+		ok, _ := path.Match(pattern, value)
+
+		return ok
+	}
+
+	// the older semantic conventions are checked too, for spans from other instrumentation
+	first := func(keys ...otelAttribute.Key) string {
+		for _, k := range keys {
+			if v, ok := attrs[k]; ok {
+				return v
+			}
+		}
+
+		return ""
+	}
+
+	route := first(otelSemConv.HTTPRouteKey, otelSemConv.URLPathKey, "http.target")
+	method := first(otelSemConv.HTTPRequestMethodKey, "http.method")
+
+	if !match(r.Name, name) || !match(r.Route, route) || !match(r.Method, method) {
+		return false
+	}
+
+	for k, v := range r.Attributes {
+		value, ok := attrs[otelAttribute.Key(k)]
+		if !ok || !match(v, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r SamplingRule) String() string {
+	fields := []string{}
+	for _, f := range []struct{ key, value string }{{"name", r.Name}, {"route", r.Route}, {"method", r.Method}} {
+		if f.value != "" {
+			fields = append(fields, f.key+"="+strconv.Quote(f.value))
+		}
+	}
+
+	keys := make([]string, 0, len(r.Attributes))
+	for k := range r.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fields = append(fields, k+"="+strconv.Quote(r.Attributes[k]))
+	}
+
+	return strings.Join(append(fields, "ratio="+strconv.FormatFloat(r.Ratio, 'g', -1, 64)), " ")
+}
+
+// NewRuleSampler creates a sampler that samples the traces with the ratio of the first rule their root span matches,
+// and with the fallback sampler if it doesn't match any of them (every trace if the fallback is nil). The rules are
+// checked against the name of the root span and the attributes it was started with, so spans with a parent follow the
+// parent's decision, and traces are kept or dropped as a whole.
+//
+//	go11y.NewRuleSampler(nil,
+//		go11y.SamplingRule{Method: "GET", Route: "/healthz", Ratio: 0.01},
+//		go11y.SamplingRule{Method: "POST", Route: "/payments", Ratio: 1},
+//	)
+func NewRuleSampler(fallback otelSDKTrace.Sampler, rules ...SamplingRule) (sampler otelSDKTrace.Sampler, fault error) {
+	if fallback == nil {
+		fallback = otelSDKTrace.AlwaysSample()
+	}
+
+	rs := &ruleSampler{fallback: fallback, rules: make([]compiledSamplingRule, len(rules))}
+
+	errs := []error{}
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i+1, err))
+		}
+
+		rs.rules[i] = compiledSamplingRule{SamplingRule: r, sampler: otelSDKTrace.TraceIDRatioBased(r.Ratio)}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return otelSDKTrace.ParentBased(rs), nil
+}
+
+type compiledSamplingRule struct {
+	SamplingRule
+	sampler otelSDKTrace.Sampler
+}
+
+// ruleSampler decides whether to sample a root span with the first rule it matches
+type ruleSampler struct {
+	fallback otelSDKTrace.Sampler
+	rules    []compiledSamplingRule
+}
+
+func (rs *ruleSampler) ShouldSample(p otelSDKTrace.SamplingParameters) otelSDKTrace.SamplingResult {
+	attrs := make(map[otelAttribute.Key]string, len(p.Attributes))
+	for _, kv := range p.Attributes {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+
+	for _, r := range rs.rules {
+		if r.matches(p.Name, attrs) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+
+	return rs.fallback.ShouldSample(p)
+}
+
+// Description lists the rules, so reloading the configuration can tell whether they changed
+func (rs *ruleSampler) Description() string {
+	rules := make([]string, len(rs.rules))
+	for i, r := range rs.rules {
+		rules[i] = "{" + r.String() + "}"
+	}
+
+	return fmt.Sprintf("RuleSampler{rules:[%s],fallback:%s}", strings.Join(rules, ","), rs.fallback.Description())
+}
+
+// SetSampler replaces the sampler that decides which traces are sampled, until the configuration is reloaded. It has
+// no effect if tracing is disabled or the tracer provider was provided with UseTracerProvider.
+func (o *Observer) SetSampler(sampler otelSDKTrace.Sampler) {
+	if sampler == nil {
+		sampler = effectiveSampler(o.settings)
+	}
+
+	o.live.sampler.swap(sampler)
+}
@@ -0,0 +1,220 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/attribute"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRuleSampler(t *testing.T) {
+	sampler, err := go11y.NewRuleSampler(otelSDKTrace.NeverSample(),
+		go11y.SamplingRule{Method: "GET", Route: "/healthz", Ratio: 0},
+		go11y.SamplingRule{Route: "/healthz", Ratio: 1},
+		go11y.SamplingRule{Name: "job *", Ratio: 1},
+		go11y.SamplingRule{Attributes: map[string]string{"tenant": "acme*"}, Ratio: 1},
+	)
+	if err != nil {
+		t.Fatalf("failed to create sampler: %v", err)
+	}
+
+	tracer := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSampler(sampler)).Tracer("test")
+
+	testCases := []struct {
+		name     string
+		span     string
+		attrs    []attribute.KeyValue
+		expected bool
+	}{
+		{
+			name:     "method and route",
+			span:     "HTTP GET /healthz",
+			attrs:    []attribute.KeyValue{attribute.String("http.request.method", "GET"), attribute.String("url.path", "/healthz")},
+			expected: false,
+		},
+		{
+			name:     "route",
+			span:     "HTTP POST /healthz",
+			attrs:    []attribute.KeyValue{attribute.String("http.method", "POST"), attribute.String("http.route", "/healthz")},
+			expected: true,
+		},
+		{
+			name:     "span name",
+			span:     "job cleanup",
+			expected: true,
+		},
+		{
+			name:     "attribute",
+			span:     "query",
+			attrs:    []attribute.KeyValue{attribute.String("tenant", "acme-eu")},
+			expected: true,
+		},
+		{
+			name:     "fallback",
+			span:     "query",
+			attrs:    []attribute.KeyValue{attribute.String("tenant", "globex")},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, span := tracer.Start(context.Background(), tc.span, otelTrace.WithAttributes(tc.attrs...))
+			defer span.End()
+
+			if span.SpanContext().IsSampled() != tc.expected {
+				t.Errorf("expected sampled to be %v, got %v", tc.expected, span.SpanContext().IsSampled())
+			}
+
+			// the child doesn't match any rule, but follows its parent
+			_, child := tracer.Start(ctx, "child")
+			defer child.End()
+
+			if child.SpanContext().IsSampled() != tc.expected {
+				t.Errorf("expected the child to follow its parent, got sampled %v", child.SpanContext().IsSampled())
+			}
+		})
+	}
+}
+
+func TestNewRuleSamplerErrors(t *testing.T) {
+	_, err := go11y.NewRuleSampler(nil,
+		go11y.SamplingRule{Route: "/ok", Ratio: 0.5},
+		go11y.SamplingRule{Route: "/too-many", Ratio: 1.5},
+		go11y.SamplingRule{Name: "[", Ratio: 1},
+	)
+	if err == nil {
+		t.Fatalf("expected an error for the invalid rules")
+	}
+
+	for _, expected := range []string{"rule 2: the ratio must be between 0 and 1", "rule 3: invalid pattern '['"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got %v", expected, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "rule 1") {
+		t.Errorf("expected the valid rule not to be reported, got %v", err)
+	}
+}
+
+func TestSamplingConfigFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expDesc  string
+		expErrs  []string
+	}{
+		{
+			name:     "ratio",
+			contents: "tracing:\n  sampling:\n    ratio: 0.25\n",
+			expDesc:  "ParentBased{root:TraceIDRatioBased{0.25}",
+		},
+		{
+			name:     "sampler",
+			contents: "tracing:\n  sampling:\n    sampler: traceidratio\n    ratio: 0.5\n",
+			expDesc:  "TraceIDRatioBased{0.5}",
+		},
+		{
+			name:     "rules",
+			contents: "tracing:\n  sampling:\n    sampler: always_off\n    rules:\n      - method: GET\n        route: /healthz\n        ratio: 0.01\n      - attributes:\n          tenant: acme\n        ratio: 1\n",
+			expDesc:  `RuleSampler{rules:[{route="/healthz" method="GET" ratio=0.01},{tenant="acme" ratio=1}],fallback:AlwaysOffSampler}`,
+		},
+		{
+			name:     "invalid",
+			contents: "tracing:\n  sampling:\n    sampler: sometimes\n    rules:\n      - route: /healthz\n      - route: /payments\n        ratio: 2\n",
+			expErrs: []string{
+				"tracing.sampling.sampler: unsupported sampler 'sometimes'",
+				"tracing.sampling.rules[0].ratio: is required",
+				"tracing.sampling.rules[1]: the ratio must be between 0 and 1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", tc.contents))
+
+			if len(tc.expErrs) != 0 {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+
+				for _, expected := range tc.expErrs {
+					if !strings.Contains(err.Error(), expected) {
+						t.Errorf("expected the error to contain %q, got %v", expected, err)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to load config file: %v", err)
+			}
+
+			_, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard))
+			if err != nil {
+				t.Fatalf("failed to create observer: %v", err)
+			}
+			defer o.Close()
+
+			sampler := o.EffectiveConfig()["tracing.sampler"]
+			if !strings.Contains(sampler.Value, tc.expDesc) || sampler.Source != go11y.SourceFile {
+				t.Errorf("expected the sampler to be %s from the file, got %+v", tc.expDesc, sampler)
+			}
+		})
+	}
+}
+
+func TestSamplingReload(t *testing.T) {
+	path := writeConfigFile(t, "go11y.yaml", "tracing:\n  sampling:\n    rules:\n      - route: /healthz\n        ratio: 0\n")
+	o := newReloadObserver(t, path, &syncBuffer{})
+
+	sampled := func() bool {
+		var sampled bool
+
+		handler := go11y.LogRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sampled = otelTrace.SpanContextFromContext(r.Context()).IsSampled()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		return sampled
+	}
+
+	if sampled() {
+		t.Errorf("expected the health check not to be sampled")
+	}
+
+	if err := os.WriteFile(path, []byte("tracing:\n  sampling:\n    rules:\n      - route: /healthz\n        ratio: 1\n"), 0o600); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	if err := o.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	if !sampled() {
+		t.Errorf("expected the health check to be sampled after the reload")
+	}
+
+	o.SetSampler(otelSDKTrace.NeverSample())
+
+	if sampled() {
+		t.Errorf("expected the health check not to be sampled after setting the sampler")
+	}
+
+	o.SetSampler(nil)
+
+	if !sampled() {
+		t.Errorf("expected the configured sampler to be restored")
+	}
+}
@@ -1,5 +1,7 @@
 package go11y
 
+import "fmt"
+
 const (
 	SeverityLowest  string = "lowest"  // No threat to system/process operation - the user can fix this themselves and continue this one operation
 	SeverityLow     string = "low"     // No threat to system/process operation - the user can fix this themselves but will need to restart the operation
@@ -7,3 +9,42 @@ const (
 	SeverityHigh    string = "high"    // The error will cause disruption to system/process operation - something outside the user's control will need to be fixed
 	SeverityHighest string = "highest" // The error will cause major disruption to system/process operation - something outside the user's control will need to be fixed, and there may be wider implications for the system/process as a whole
 )
+
+// DefaultErrorStatusSeverity is the lowest severity of the errors that set the status of the active span to an error,
+// so the errors the user can fix themselves don't mark the span as failed
+const DefaultErrorStatusSeverity = SeverityMedium
+
+// ErrorStatusSeverityEnv is the environment variable LoadConfig reads the lowest severity that sets the span status from
+const ErrorStatusSeverityEnv = "GO11Y_ERROR_STATUS_SEVERITY"
+
+// severities are the severities from the lowest to the highest
+var severities = []string{SeverityLowest, SeverityLow, SeverityMedium, SeverityHigh, SeverityHighest}
+
+// severityRank returns the position of the severity from the lowest, treating an unknown severity as the highest so it
+// isn't hidden
+func severityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return len(severities)
+}
+
+// validateSeverity returns an error if the severity isn't one of the Severity constants
+func validateSeverity(severity string) (fault error) {
+	if severityRank(severity) == len(severities) {
+		return fmt.Errorf("must be %s, %s, %s, %s or %s, got '%s'", SeverityLowest, SeverityLow, SeverityMedium, SeverityHigh, SeverityHighest, severity)
+	}
+
+	return nil
+}
+
+// WithErrorStatusSeverity sets the lowest severity of the errors logged with Observer.Error that set the status of the
+// active span to an error, instead of DefaultErrorStatusSeverity. Fatal errors always set it.
+func WithErrorStatusSeverity(severity string) Option {
+	return func(s *settings) {
+		s.errorStatusSeverity = severity
+	}
+}
@@ -0,0 +1,381 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The formats the logs can be written in
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// The types of sink the logs can be written to
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+// SinkConfig describes somewhere the logs are written to
+type SinkConfig struct {
+	Type     string `yaml:"type" json:"type"`                             // one of stdout, stderr, file or syslog
+	Format   string `yaml:"format,omitempty" json:"format,omitempty"`     // json (the default) or text, for the stdout, stderr and file sinks
+	Path     string `yaml:"path,omitempty" json:"path,omitempty"`         // the file the logs are appended to, for the file sink
+	Network  string `yaml:"network,omitempty" json:"network,omitempty"`   // the network of the syslog server, such as udp, tcp or unix
+	Address  string `yaml:"address,omitempty" json:"address,omitempty"`   // the address of the syslog server
+	Facility string `yaml:"facility,omitempty" json:"facility,omitempty"` // the syslog facility, such as local0
+}
+
+// validate returns the problems with the sink, prefixed with the path of the sink in the configuration
+func (s SinkConfig) validate(prefix string) (errs []error) {
+	switch s.Type {
+	case SinkStdout, SinkStderr:
+	case SinkFile:
+		if s.Path == "" {
+			errs = append(errs, fmt.Errorf("%s.path: is required for a file sink", prefix))
+		}
+	case SinkSyslog:
+		if s.Facility != "" {
+			if _, err := ParseSyslogFacility(s.Facility); err != nil {
+				errs = append(errs, fmt.Errorf("%s.facility: %w", prefix, err))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.type: must be one of %s, %s, %s or %s, got '%s'", prefix, SinkStdout, SinkStderr, SinkFile, SinkSyslog, s.Type))
+	}
+
+	if err := validateFormat(s.Format); err != nil {
+		errs = append(errs, fmt.Errorf("%s.format: %w", prefix, err))
+	}
+
+	return errs
+}
+
+func validateFormat(format string) (fault error) {
+	switch format {
+	case "", FormatJSON, FormatText:
+		return nil
+	default:
+		return fmt.Errorf("must be %s or %s, got '%s'", FormatJSON, FormatText, format)
+	}
+}
+
+// formatHandler creates a handler that writes the logs to w in the format
+func formatHandler(format string, w io.Writer, ho *slog.HandlerOptions) slog.Handler {
+	if format == FormatText {
+		return slog.NewTextHandler(w, ho)
+	}
+
+	return slog.NewJSONHandler(w, ho)
+}
+
+// sinkHandler creates a handler that writes the logs to the sink, and returns the sink's closer (if it needs closing)
+func sinkHandler(sink SinkConfig, format string, appName string, ho *slog.HandlerOptions) (handler slog.Handler, closer io.Closer, fault error) {
+	if sink.Format != "" {
+		format = sink.Format
+	}
+
+	switch sink.Type {
+	case SinkStdout:
+		return formatHandler(format, os.Stdout, ho), nil, nil
+	case SinkStderr:
+		return formatHandler(format, os.Stderr, ho), nil, nil
+	case SinkFile:
+		f, err := os.OpenFile(sink.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not open log file '%s': %w", sink.Path, err)
+		}
+
+		return formatHandler(format, f, ho), f, nil
+	case SinkSyslog:
+		opts := &SyslogOptions{
+			Level:   ho.Level,
+			AppName: appName,
+		}
+
+		if sink.Facility != "" {
+			facility, err := ParseSyslogFacility(sink.Facility)
+			if err != nil {
+				return nil, nil, err
+			}
+			opts.Facility = facility
+		}
+
+		h, err := NewSyslogHandler(sink.Network, sink.Address, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return h, h, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown sink type '%s'", sink.Type)
+	}
+}
+
+// multiHandler sends each record to all of its handlers that are enabled for the record's level
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	m2 := make(multiHandler, len(m))
+	for i, h := range m {
+		m2[i] = h.WithAttrs(attrs)
+	}
+
+	return m2
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	m2 := make(multiHandler, len(m))
+	for i, h := range m {
+		m2[i] = h.WithGroup(name)
+	}
+
+	return m2
+}
+
+// packageLevel is the level for the records logged by the functions in a package (or its sub-packages)
+type packageLevel struct {
+	prefix string
+	level  slog.Level
+}
+
+// packageLevelSet holds the default level and the levels for packages, which can be replaced while they are in use
+type packageLevelSet struct {
+	mu           sync.RWMutex
+	defaultLevel slog.Level
+	levels       []packageLevel // longest prefix first
+	minLevel     slog.Level
+}
+
+func newPackageLevelSet(defaultLevel slog.Level, levels map[string]slog.Level) *packageLevelSet {
+	p := &packageLevelSet{}
+	p.set(defaultLevel, levels)
+
+	return p
+}
+
+// set replaces the default level and the levels for packages
+func (p *packageLevelSet) set(defaultLevel slog.Level, levels map[string]slog.Level) {
+	ordered := make([]packageLevel, 0, len(levels))
+	for prefix, level := range levels {
+		ordered = append(ordered, packageLevel{prefix: prefix, level: level})
+	}
+
+	sort.Slice(ordered, func(i, j int) bool {
+		return len(ordered[i].prefix) > len(ordered[j].prefix)
+	})
+
+	p.mu.Lock()
+	p.defaultLevel = defaultLevel
+	p.levels = ordered
+	p.minLevel = minPackageLevel(defaultLevel, levels)
+	p.mu.Unlock()
+}
+
+// min returns the lowest of the default level and the package levels
+func (p *packageLevelSet) min() slog.Level {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.minLevel
+}
+
+// levelFor returns the level for the package of the function at pc
+func (p *packageLevelSet) levelFor(pc uintptr) slog.Level {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if pc == 0 || len(p.levels) == 0 {
+		return p.defaultLevel
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	for _, pl := range p.levels {
+		rest, ok := strings.CutPrefix(frame.Function, pl.prefix)
+		if ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+			return pl.level
+		}
+	}
+
+	return p.defaultLevel
+}
+
+// minPackageLevel returns the lowest of the default level and the package levels
+func minPackageLevel(defaultLevel slog.Level, levels map[string]slog.Level) slog.Level {
+	lowest := defaultLevel
+	for _, level := range levels {
+		lowest = min(lowest, level)
+	}
+
+	return lowest
+}
+
+// packageLevelHandler drops the records that are below the level for the package of the function that logged them, or
+// below the default level if there isn't a level for the package. The next handler must allow the lowest of the levels.
+type packageLevelHandler struct {
+	next   slog.Handler
+	levels *packageLevelSet
+}
+
+func (h *packageLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.min() && h.next.Enabled(ctx, level)
+}
+
+func (h *packageLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.levelFor(r.PC) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *packageLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &packageLevelHandler{next: h.next.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *packageLevelHandler) WithGroup(name string) slog.Handler {
+	return &packageLevelHandler{next: h.next.WithGroup(name), levels: h.levels}
+}
+
+// handlerSwitch holds the handler that the switchHandlers send records to, which can be replaced while they are in use
+type handlerSwitch struct {
+	current atomic.Pointer[switchedHandler]
+}
+
+type switchedHandler struct {
+	handler slog.Handler
+	mu      sync.RWMutex // held for reading while a record is handled, and for writing to retire the handler
+	retired bool
+}
+
+func newHandlerSwitch(handler slog.Handler) *handlerSwitch {
+	sw := &handlerSwitch{}
+	sw.swap(handler)
+
+	return sw
+}
+
+// swap replaces the handler, returning the one it replaced
+func (sw *handlerSwitch) swap(handler slog.Handler) (previous *switchedHandler) {
+	return sw.current.Swap(&switchedHandler{handler: handler})
+}
+
+// retire waits for the records that are being handled to be written, after which the records go to the handler that
+// replaced it, so its sinks can be closed without losing any
+func (sh *switchedHandler) retire() {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.retired = true
+}
+
+// switchHandler sends records to the current handler of its switch, with the attrs and groups that were added to it
+// applied to that handler. The result is cached until the switch's handler is replaced.
+type switchHandler struct {
+	sw    *handlerSwitch
+	with  []func(slog.Handler) slog.Handler
+	cache atomic.Pointer[switchCache]
+}
+
+type switchCache struct {
+	base    *switchedHandler
+	handler slog.Handler
+}
+
+func (h *switchHandler) handler() (base *switchedHandler, handler slog.Handler) {
+	base = h.sw.current.Load()
+
+	if c := h.cache.Load(); c != nil && c.base == base {
+		return base, c.handler
+	}
+
+	handler = base.handler
+	for _, with := range h.with {
+		handler = with(handler)
+	}
+
+	h.cache.Store(&switchCache{base: base, handler: handler})
+
+	return base, handler
+}
+
+func (h *switchHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	_, handler := h.handler()
+
+	return handler.Enabled(ctx, level)
+}
+
+// Handle sends the record to the current handler, trying again with the handler that replaced it if it was retired
+// before the record could be handled
+func (h *switchHandler) Handle(ctx context.Context, r slog.Record) error {
+	for {
+		base, handler := h.handler()
+
+		base.mu.RLock()
+		if !base.retired {
+			err := handler.Handle(ctx, r)
+			base.mu.RUnlock()
+
+			return err
+		}
+		base.mu.RUnlock()
+	}
+}
+
+func (h *switchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.extend(func(next slog.Handler) slog.Handler {
+		return next.WithAttrs(attrs)
+	})
+}
+
+func (h *switchHandler) WithGroup(name string) slog.Handler {
+	return h.extend(func(next slog.Handler) slog.Handler {
+		return next.WithGroup(name)
+	})
+}
+
+func (h *switchHandler) extend(with func(slog.Handler) slog.Handler) *switchHandler {
+	return &switchHandler{
+		sw:   h.sw,
+		with: append(append([]func(slog.Handler) slog.Handler{}, h.with...), with),
+	}
+}
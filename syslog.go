@@ -0,0 +1,437 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFacility is the facility part of the syslog priority, as defined in RFC 5424 section 6.2.1. The kernel facility
+// (0) is only used by the kernel, so there isn't a constant for it and the zero value means the facility isn't set.
+type SyslogFacility int
+
+const (
+	FacilityUser SyslogFacility = iota + 1
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityLocal0 SyslogFacility = iota + 5
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilityNames = map[string]SyslogFacility{
+	"user":     FacilityUser,
+	"mail":     FacilityMail,
+	"daemon":   FacilityDaemon,
+	"auth":     FacilityAuth,
+	"syslog":   FacilitySyslog,
+	"lpr":      FacilityLPR,
+	"news":     FacilityNews,
+	"uucp":     FacilityUUCP,
+	"cron":     FacilityCron,
+	"authpriv": FacilityAuthPriv,
+	"ftp":      FacilityFTP,
+	"local0":   FacilityLocal0,
+	"local1":   FacilityLocal1,
+	"local2":   FacilityLocal2,
+	"local3":   FacilityLocal3,
+	"local4":   FacilityLocal4,
+	"local5":   FacilityLocal5,
+	"local6":   FacilityLocal6,
+	"local7":   FacilityLocal7,
+}
+
+// ParseSyslogFacility converts a facility name (e.g. "daemon" or "local3") to a SyslogFacility
+func ParseSyslogFacility(name string) (facility SyslogFacility, fault error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "kern" {
+		return 0, fmt.Errorf("the syslog facility '%s' is reserved for the kernel", name)
+	}
+
+	f, ok := facilityNames[key]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility '%s'", name)
+	}
+
+	return f, nil
+}
+
+// SyslogSeverity is the severity part of the syslog priority, as defined in RFC 5424 section 6.2.1
+type SyslogSeverity int
+
+const (
+	SyslogEmergency SyslogSeverity = iota
+	SyslogAlert
+	SyslogCritical
+	SyslogError
+	SyslogWarning
+	SyslogNotice
+	SyslogInformational
+	SyslogDebug
+)
+
+// SyslogSeverityFor maps a go11y log level onto the matching syslog severity.
+// Levels between the named go11y levels are mapped to the severity of the nearest level below them.
+func SyslogSeverityFor(level slog.Level) SyslogSeverity {
+	switch {
+	case level >= LevelFatal:
+		return SyslogCritical
+	case level >= LevelError:
+		return SyslogError
+	case level >= LevelWarning:
+		return SyslogWarning
+	case level >= LevelNotice:
+		return SyslogNotice
+	case level >= LevelInfo:
+		return SyslogInformational
+	default:
+		return SyslogDebug
+	}
+}
+
+// DefaultSyslogSDID is the structured data ID used for the stable args when SyslogOptions.SDID is empty.
+// 32473 is the private enterprise number reserved for documentation and examples by RFC 5612.
+const DefaultSyslogSDID = "go11y@32473"
+
+// SyslogOptions configures a SyslogHandler
+type SyslogOptions struct {
+	Facility SyslogFacility // Facility used in the priority of every message, defaults to FacilityUser
+	Level    slog.Leveler   // Minimum level to send, defaults to LevelInfo
+	AppName  string         // APP-NAME header field, defaults to the name of the executable
+	Hostname string         // HOSTNAME header field, defaults to os.Hostname()
+	SDID     string         // SD-ID of the structured data element holding the stable args, defaults to DefaultSyslogSDID
+}
+
+// SyslogHandler is a slog.Handler that sends RFC 5424 formatted messages to a syslog server over a unix socket, UDP
+// or TCP. Args added with Extend (the stable args) are sent as structured data, while the ephemeral args of each call
+// are appended to the message as key=value pairs.
+type SyslogHandler struct {
+	opts   SyslogOptions
+	conn   *syslogConn
+	attrs  []slog.Attr
+	groups []string
+}
+
+type syslogConn struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+}
+
+// NewSyslogHandler connects to the syslog server at address using network, which may be "unix", "unixgram", "udp" or
+// "tcp" (or any of their variants accepted by net.Dial). If both network and address are empty, the local syslog
+// socket is used.
+func NewSyslogHandler(network, address string, opts *SyslogOptions) (handler *SyslogHandler, fault error) {
+	o := SyslogOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	// the facility defaults to user when it isn't set, as it does for syslog(3)
+	if o.Facility == 0 {
+		o.Facility = FacilityUser
+	}
+
+	if o.Level == nil {
+		o.Level = LevelInfo
+	}
+
+	if o.AppName == "" {
+		o.AppName = filepath.Base(os.Args[0])
+	}
+
+	if o.Hostname == "" {
+		o.Hostname, _ = os.Hostname()
+	}
+
+	if o.SDID == "" {
+		o.SDID = DefaultSyslogSDID
+	}
+
+	c := &syslogConn{
+		network: network,
+		address: address,
+	}
+
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+
+	return &SyslogHandler{
+		opts: o,
+		conn: c,
+	}, nil
+}
+
+// Enabled reports whether the handler sends records at the given level.
+func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// WithAttrs returns a new SyslogHandler whose structured data includes the given attributes.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	h2.attrs = append(h2.attrs, h.attrs...)
+
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, slog.Attr{Key: h.qualify(a.Key), Value: a.Value})
+	}
+
+	return &h2
+}
+
+// WithGroup returns a new SyslogHandler that prefixes the keys of all following attributes with the group name.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+
+	return &h2
+}
+
+// Handle formats the record as an RFC 5424 message and sends it to the syslog server.
+func (h *SyslogHandler) Handle(_ context.Context, r slog.Record) error {
+	return h.conn.write(h.format(r))
+}
+
+// Close closes the connection to the syslog server.
+func (h *SyslogHandler) Close() error {
+	return h.conn.close()
+}
+
+func (h *SyslogHandler) qualify(key string) string {
+	if len(h.groups) == 0 {
+		return key
+	}
+
+	return strings.Join(h.groups, ".") + "." + key
+}
+
+func (h *SyslogHandler) format(r slog.Record) []byte {
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "<%d>1 %s %s %s %d %s ",
+		int(h.opts.Facility)*8+int(SyslogSeverityFor(r.Level)),
+		ts.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(h.opts.Hostname, 255),
+		syslogHeaderField(h.opts.AppName, 48),
+		os.Getpid(),
+		syslogHeaderField(LevelName(r.Level), 32),
+	)
+
+	if len(h.attrs) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[")
+		b.WriteString(syslogName(h.opts.SDID))
+		for _, a := range h.attrs {
+			flattenAttr("", a, func(key string, value slog.Value) {
+				fmt.Fprintf(b, ` %s="%s"`, syslogName(key), syslogEscape(value.String()))
+			})
+		}
+		b.WriteString("]")
+	}
+
+	b.WriteString(" ")
+	b.WriteString(r.Message)
+
+	r.Attrs(func(a slog.Attr) bool {
+		flattenAttr(strings.Join(h.groups, "."), a, func(key string, value slog.Value) {
+			v := value.String()
+			if strings.ContainsAny(v, " \"=") || v == "" {
+				v = strconv.Quote(v)
+			}
+			fmt.Fprintf(b, " %s=%s", key, v)
+		})
+
+		return true
+	})
+
+	return []byte(b.String())
+}
+
+// flattenAttr calls fn for every leaf of the attribute, joining the keys of nested groups with dots
+func flattenAttr(prefix string, a slog.Attr, fn func(key string, value slog.Value)) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		fn(key, a.Value)
+		return
+	}
+
+	for _, ga := range a.Value.Group() {
+		flattenAttr(key, ga, fn)
+	}
+}
+
+// syslogHeaderField returns the value as a valid header field: printable US-ASCII without spaces, truncated to limit,
+// or the NILVALUE if it is empty
+func syslogHeaderField(value string, limit int) string {
+	f := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+
+	if f == "" {
+		return "-"
+	}
+
+	if len(f) > limit {
+		f = f[:limit]
+	}
+
+	return f
+}
+
+// syslogName returns the value as a valid SD-NAME: printable US-ASCII without '=', ' ', ']' or '"', at most 32 chars
+func syslogName(name string) string {
+	n := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(n) > 32 {
+		n = n[:32]
+	}
+
+	return n
+}
+
+// syslogEscape escapes the characters that must be escaped in a PARAM-VALUE
+func syslogEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+func (c *syslogConn) dial() (fault error) {
+	if c.network == "" && c.address == "" {
+		for _, path := range localSyslogPaths {
+			for _, network := range []string{"unixgram", "unix"} {
+				conn, err := net.Dial(network, path)
+				if err == nil {
+					c.network, c.address, c.conn = network, path, conn
+					return nil
+				}
+			}
+		}
+
+		return errors.New("could not find a local syslog socket")
+	}
+
+	if c.network == "unix" {
+		// syslog daemons usually listen on a datagram socket, so try that before a stream socket
+		conn, err := net.Dial("unixgram", c.address)
+		if err == nil {
+			c.network, c.conn = "unixgram", conn
+			return nil
+		}
+	}
+
+	conn, err := net.Dial(c.network, c.address)
+	if err != nil {
+		return fmt.Errorf("could not connect to syslog at %s://%s: %w", c.network, c.address, err)
+	}
+
+	c.conn = conn
+
+	return nil
+}
+
+// write sends the message, using octet-counting framing (RFC 6587) for stream connections, and reconnects once if
+// the write fails
+func (c *syslogConn) write(msg []byte) (fault error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isStream() {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	if c.conn != nil {
+		if _, err := c.conn.Write(msg); err == nil {
+			return nil
+		}
+
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+
+	if err := c.dial(); err != nil {
+		return err
+	}
+
+	if _, err := c.conn.Write(msg); err != nil {
+		return fmt.Errorf("could not write to syslog: %w", err)
+	}
+
+	return nil
+}
+
+func (c *syslogConn) isStream() bool {
+	switch c.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *syslogConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
@@ -0,0 +1,254 @@
+package go11y_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+)
+
+func TestSyslogSeverityFor(t *testing.T) {
+	testCases := []struct {
+		level    slog.Level
+		expected go11y.SyslogSeverity
+	}{
+		{go11y.LevelDevelop, go11y.SyslogDebug},
+		{go11y.LevelDebug, go11y.SyslogDebug},
+		{go11y.LevelInfo, go11y.SyslogInformational},
+		{go11y.LevelNotice, go11y.SyslogNotice},
+		{go11y.LevelWarning, go11y.SyslogWarning},
+		{go11y.LevelError, go11y.SyslogError},
+		{go11y.LevelFatal, go11y.SyslogCritical},
+	}
+
+	for _, tc := range testCases {
+		if got := go11y.SyslogSeverityFor(tc.level); got != tc.expected {
+			t.Errorf("expected level %s to map to severity %d, got %d", tc.level, tc.expected, got)
+		}
+	}
+}
+
+func TestSyslogHandler(t *testing.T) {
+	t.Setenv("ENV", "test")
+	t.Setenv("LOG_LEVEL", "develop")
+
+	t.Run("udp", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen on udp: %v", err)
+		}
+		defer func() {
+			_ = pc.Close()
+		}()
+
+		msg := logToSyslog(t, "udp", pc.LocalAddr().String(), func() string {
+			return readPacket(t, pc)
+		})
+
+		checkSyslogMessage(t, msg)
+	})
+
+	t.Run("unixgram", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "syslog.sock")
+
+		pc, err := net.ListenPacket("unixgram", path)
+		if err != nil {
+			t.Fatalf("failed to listen on unix socket: %v", err)
+		}
+		defer func() {
+			_ = pc.Close()
+		}()
+
+		msg := logToSyslog(t, "unix", path, func() string {
+			return readPacket(t, pc)
+		})
+
+		checkSyslogMessage(t, msg)
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen on tcp: %v", err)
+		}
+		defer func() {
+			_ = l.Close()
+		}()
+
+		conns := make(chan net.Conn, 1)
+		go func() {
+			c, err := l.Accept()
+			if err == nil {
+				conns <- c
+			}
+		}()
+
+		var r *bufio.Reader
+		msg := logToSyslog(t, "tcp", l.Addr().String(), func() string {
+			if r == nil {
+				c := <-conns
+				t.Cleanup(func() {
+					_ = c.Close()
+				})
+
+				_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+				r = bufio.NewReader(c)
+			}
+
+			// octet-counting framing: MSG-LEN SP SYSLOG-MSG
+			length, err := r.ReadString(' ')
+			if err != nil {
+				t.Fatalf("failed to read message length: %v", err)
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				t.Fatalf("invalid message length %q: %v", length, err)
+			}
+
+			buf := make([]byte, n)
+			if _, err := r.Read(buf); err != nil {
+				t.Fatalf("failed to read message: %v", err)
+			}
+
+			return string(buf)
+		})
+
+		checkSyslogMessage(t, msg)
+	})
+}
+
+func TestSyslogFacility(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on udp: %v", err)
+	}
+	defer func() {
+		_ = pc.Close()
+	}()
+
+	testCases := []struct {
+		name     string
+		facility go11y.SyslogFacility
+		expected go11y.SyslogFacility
+	}{
+		{name: "unset", expected: go11y.FacilityUser},
+		{name: "daemon", facility: go11y.FacilityDaemon, expected: go11y.FacilityDaemon},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := go11y.NewSyslogHandler("udp", pc.LocalAddr().String(), &go11y.SyslogOptions{Facility: tc.facility})
+			if err != nil {
+				t.Fatalf("failed to create syslog handler: %v", err)
+			}
+			defer func() {
+				_ = h.Close()
+			}()
+
+			if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), go11y.LevelInfo, "facility", 0)); err != nil {
+				t.Fatalf("failed to send the message: %v", err)
+			}
+
+			prefix := fmt.Sprintf("<%d>1 ", int(tc.expected)*8+int(go11y.SyslogInformational))
+			if msg := readPacket(t, pc); !strings.HasPrefix(msg, prefix) {
+				t.Errorf("expected message to start with %q, got %q", prefix, msg)
+			}
+		})
+	}
+
+	if _, err := go11y.ParseSyslogFacility("kern"); err == nil || !strings.Contains(err.Error(), "reserved for the kernel") {
+		t.Errorf("expected an error for the kernel facility, got %v", err)
+	}
+}
+
+func logToSyslog(t *testing.T, network, address string, read func() string) (message string) {
+	t.Helper()
+
+	h, err := go11y.NewSyslogHandler(network, address, &go11y.SyslogOptions{
+		Facility: go11y.FacilityLocal3,
+		Level:    go11y.LevelDebug,
+		AppName:  "go11y-test",
+		Hostname: "testhost",
+	})
+	if err != nil {
+		t.Fatalf("failed to create syslog handler: %v", err)
+	}
+	defer func() {
+		_ = h.Close()
+	}()
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	_, o, err := go11y.InitialiseWithHandler(context.Background(), cfg, h, "tenant", "acme")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer func() {
+		o.Close()
+	}()
+
+	o.Develop("filtered out by the handler level")
+	o.Notice("syslog notice", "attempt", 2, "note", "with spaces")
+
+	// the observer logs its tracing mode at debug when it starts, before the notice
+	if message = read(); strings.Contains(message, "tracing configured") {
+		message = read()
+	}
+
+	return message
+}
+
+func readPacket(t *testing.T, pc net.PacketConn) string {
+	t.Helper()
+
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+
+	return string(buf[:n])
+}
+
+func checkSyslogMessage(t *testing.T, msg string) {
+	t.Helper()
+
+	pri := int(go11y.FacilityLocal3)*8 + int(go11y.SyslogNotice)
+	prefix := fmt.Sprintf("<%d>1 ", pri)
+
+	if !strings.HasPrefix(msg, prefix) {
+		t.Fatalf("expected message to start with %q, got %q", prefix, msg)
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(msg, prefix), " ", 6)
+	if len(fields) != 6 {
+		t.Fatalf("expected 6 fields after the version, got %d in %q", len(fields), msg)
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+		t.Errorf("expected an RFC 3339 timestamp, got %q: %v", fields[0], err)
+	}
+
+	if fields[1] != "testhost" || fields[2] != "go11y-test" || fields[4] != "NOTICE" {
+		t.Errorf("unexpected header fields: %q", fields[:5])
+	}
+
+	expected := `[go11y@32473 tenant="acme"] syslog notice attempt=2 note="with spaces"`
+	if fields[5] != expected {
+		t.Errorf("expected structured data and message %q, got %q", expected, fields[5])
+	}
+}
@@ -0,0 +1,340 @@
+package go11y
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// The defaults for tail sampling
+const (
+	DefaultTailWindow    = 30 * time.Second
+	DefaultTailMaxTraces = 10000
+	DefaultTailMaxSpans  = 1000
+)
+
+// TailSamplingConfig configures which traces the tail sampling processor keeps once they have ended
+type TailSamplingConfig struct {
+	Window           time.Duration     // how long the spans of a trace are buffered for when its root span doesn't end, DefaultTailWindow if it is zero
+	Latency          time.Duration     // traces with a span that lasts at least this long are kept, unless it is zero
+	Attributes       map[string]string // traces with a span that has any of these attributes are kept, the values are globs
+	Ratio            float64           // the fraction of the other traces that are kept
+	MaxTraces        int               // the most traces that are buffered at once, DefaultTailMaxTraces if it is zero
+	MaxSpansPerTrace int               // the most spans that are buffered for a trace, DefaultTailMaxSpans if it is zero
+}
+
+func (c TailSamplingConfig) withDefaults() TailSamplingConfig {
+	if c.Window <= 0 {
+		c.Window = DefaultTailWindow
+	}
+
+	if c.MaxTraces <= 0 {
+		c.MaxTraces = DefaultTailMaxTraces
+	}
+
+	if c.MaxSpansPerTrace <= 0 {
+		c.MaxSpansPerTrace = DefaultTailMaxSpans
+	}
+
+	return c
+}
+
+func (c TailSamplingConfig) validate(source func(field string) string) (errs []error) {
+	if c.Window < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", source("window"), c.Window))
+	}
+
+	if c.Latency < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", source("latency"), c.Latency))
+	}
+
+	if c.Ratio < 0 || c.Ratio > 1 {
+		errs = append(errs, fmt.Errorf("%s: must be between 0 and 1, got %v", source("ratio"), c.Ratio))
+	}
+
+	for k, v := range c.Attributes {
+		if _, err := path.Match(v, ""); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid pattern '%s' for %s: %w", source("attributes"), v, k, err))
+		}
+	}
+
+	if c.MaxTraces < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative, got %d", source("max_traces"), c.MaxTraces))
+	}
+
+	if c.MaxSpansPerTrace < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative, got %d", source("max_spans_per_trace"), c.MaxSpansPerTrace))
+	}
+
+	return errs
+}
+
+// String describes the configuration, with the defaults filled in
+func (c TailSamplingConfig) String() string {
+	c = c.withDefaults()
+
+	attrs := make([]string, 0, len(c.Attributes))
+	for k, v := range c.Attributes {
+		attrs = append(attrs, k+"="+v)
+	}
+	sort.Strings(attrs)
+
+	return fmt.Sprintf("window=%s latency=%s attributes=%s ratio=%s max_traces=%d max_spans_per_trace=%d",
+		c.Window, c.Latency, strings.Join(attrs, ","), strconv.FormatFloat(c.Ratio, 'g', -1, 64), c.MaxTraces, c.MaxSpansPerTrace)
+}
+
+// NewTailSamplingProcessor creates a span processor that buffers the spans of each trace, and decides whether to pass
+// them on to next when the trace's root span ends. Traces with a span that has an error status, an Error or Fatal log
+// event, a latency over the threshold or one of the attributes are kept, and a ratio of the others. If the root span
+// doesn't end within the window, the decision is made with the spans that have ended by then. The spans that end after
+// the decision follow it, as long as the decision is remembered (for the window, or until the most decisions are
+// remembered and it is the oldest). When the most traces are buffered, the oldest one is decided early, and the spans
+// beyond the most for a trace are dropped. ForceFlush leaves the buffered traces to be decided as usual, while Shutdown
+// decides them with the spans that have ended so far.
+//
+// Only the spans the sampler sampled are seen, so the sampler should sample every trace that could be kept.
+func NewTailSamplingProcessor(next otelSDKTrace.SpanProcessor, cfg TailSamplingConfig) otelSDKTrace.SpanProcessor {
+	ts := &tailSampler{
+		next:      next,
+		cfg:       cfg.withDefaults(),
+		ratio:     otelSDKTrace.TraceIDRatioBased(cfg.Ratio),
+		traces:    map[otelTrace.TraceID]*pendingTrace{},
+		pending:   list.New(),
+		decided:   map[otelTrace.TraceID]*list.Element{},
+		decisions: list.New(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go ts.expire()
+
+	return ts
+}
+
+type tailSampler struct {
+	next  otelSDKTrace.SpanProcessor
+	cfg   TailSamplingConfig
+	ratio otelSDKTrace.Sampler
+
+	mu        sync.Mutex
+	traces    map[otelTrace.TraceID]*pendingTrace
+	pending   *list.List // the buffered traces, oldest first
+	decided   map[otelTrace.TraceID]*list.Element
+	decisions *list.List // the remembered decisions, oldest first
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// pendingTrace holds the spans of a trace that hasn't been decided yet
+type pendingTrace struct {
+	id      otelTrace.TraceID
+	spans   []otelSDKTrace.ReadOnlySpan
+	keep    bool          // whether any of the spans make the trace worth keeping
+	started time.Time     // when the first span was buffered
+	elem    *list.Element // the trace's place in the pending list
+}
+
+type decidedTrace struct {
+	id   otelTrace.TraceID
+	keep bool
+	at   time.Time
+}
+
+func (ts *tailSampler) OnStart(parent context.Context, s otelSDKTrace.ReadWriteSpan) {
+	ts.next.OnStart(parent, s)
+}
+
+func (ts *tailSampler) OnEnd(s otelSDKTrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+
+	id := s.SpanContext().TraceID()
+	now := time.Now()
+
+	ts.mu.Lock()
+
+	if d, ok := ts.decided[id]; ok {
+		keep := d.Value.(decidedTrace).keep
+		ts.mu.Unlock()
+
+		if keep {
+			ts.next.OnEnd(s)
+		}
+
+		return
+	}
+
+	export := []otelSDKTrace.ReadOnlySpan{}
+
+	t, ok := ts.traces[id]
+	if !ok {
+		if len(ts.traces) >= ts.cfg.MaxTraces {
+			export = append(export, ts.decideOldest(now)...)
+		}
+
+		t = &pendingTrace{id: id, started: now}
+		t.elem = ts.pending.PushBack(t)
+		ts.traces[id] = t
+	}
+
+	if len(t.spans) < ts.cfg.MaxSpansPerTrace {
+		t.spans = append(t.spans, s)
+	}
+	t.keep = t.keep || ts.keeps(s)
+
+	// the root span of the trace in this process has ended, so every span that belongs to it should have too
+	if !s.Parent().IsValid() || s.Parent().IsRemote() {
+		export = append(export, ts.decide(t, now)...)
+	}
+
+	ts.mu.Unlock()
+
+	for _, span := range export {
+		ts.next.OnEnd(span)
+	}
+}
+
+// keeps returns true if the span makes its trace worth keeping
+func (ts *tailSampler) keeps(s otelSDKTrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+
+	if ts.cfg.Latency > 0 && s.EndTime().Sub(s.StartTime()) >= ts.cfg.Latency {
+		return true
+	}
+
+	for _, e := range s.Events() {
+		for _, kv := range e.Attributes {
+			if string(kv.Key) == FieldSeverityNumber && kv.Value.AsInt64() >= int64(OTelSeverityError) {
+				return true
+			}
+		}
+	}
+
+	for _, kv := range s.Attributes() {
+		if pattern, ok := ts.cfg.Attributes[string(kv.Key)]; ok {
+			if match, _ := path.Match(pattern, kv.Value.Emit()); match {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decide removes the trace from the buffer and remembers whether it was kept (forgetting the oldest decision if the
+// most are remembered), returning its spans if it was. It must be called with the lock held.
+func (ts *tailSampler) decide(t *pendingTrace, now time.Time) []otelSDKTrace.ReadOnlySpan {
+	delete(ts.traces, t.id)
+	ts.pending.Remove(t.elem)
+
+	keep := t.keep || ts.ratio.ShouldSample(otelSDKTrace.SamplingParameters{TraceID: t.id}).Decision == otelSDKTrace.RecordAndSample
+
+	if len(ts.decided) >= ts.cfg.MaxTraces {
+		ts.forget(ts.decisions.Front())
+	}
+	ts.decided[t.id] = ts.decisions.PushBack(decidedTrace{id: t.id, keep: keep, at: now})
+
+	if !keep {
+		return nil
+	}
+
+	return t.spans
+}
+
+// forget removes the decision. It must be called with the lock held.
+func (ts *tailSampler) forget(e *list.Element) {
+	delete(ts.decided, e.Value.(decidedTrace).id)
+	ts.decisions.Remove(e)
+}
+
+// decideOldest decides the trace that has been buffered the longest, to make room for another. It must be called with
+// the lock held.
+func (ts *tailSampler) decideOldest(now time.Time) []otelSDKTrace.ReadOnlySpan {
+	oldest := ts.pending.Front()
+	if oldest == nil {
+		return nil
+	}
+
+	return ts.decide(oldest.Value.(*pendingTrace), now)
+}
+
+// expire decides the traces that have been buffered for longer than the window, and forgets the old decisions
+func (ts *tailSampler) expire() {
+	defer close(ts.done)
+
+	ticker := time.NewTicker(ts.cfg.Window / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ts.stop:
+			return
+		case now := <-ticker.C:
+			ts.decideOldestWhile(func(t *pendingTrace) bool { return now.Sub(t.started) >= ts.cfg.Window })
+
+			ts.mu.Lock()
+			for e := ts.decisions.Front(); e != nil && now.Sub(e.Value.(decidedTrace).at) >= ts.cfg.Window; e = ts.decisions.Front() {
+				ts.forget(e)
+			}
+			ts.mu.Unlock()
+		}
+	}
+}
+
+// decideOldestWhile decides the buffered traces, from the oldest, until one doesn't match, and passes on the spans of
+// those that are kept
+func (ts *tailSampler) decideOldestWhile(match func(t *pendingTrace) bool) {
+	now := time.Now()
+	export := []otelSDKTrace.ReadOnlySpan{}
+
+	ts.mu.Lock()
+	for e := ts.pending.Front(); e != nil && match(e.Value.(*pendingTrace)); e = ts.pending.Front() {
+		export = append(export, ts.decide(e.Value.(*pendingTrace), now)...)
+	}
+	ts.mu.Unlock()
+
+	for _, s := range export {
+		ts.next.OnEnd(s)
+	}
+}
+
+// ForceFlush flushes the next processor. The buffered traces are still in progress, so they are left to be decided
+// when their root spans end or the window passes.
+func (ts *tailSampler) ForceFlush(ctx context.Context) (fault error) {
+	return ts.next.ForceFlush(ctx)
+}
+
+// Shutdown decides the buffered traces and shuts down the next processor
+func (ts *tailSampler) Shutdown(ctx context.Context) (fault error) {
+	ts.stopOnce.Do(func() {
+		close(ts.stop)
+		<-ts.done
+	})
+
+	ts.decideOldestWhile(func(*pendingTrace) bool { return true })
+
+	return ts.next.Shutdown(ctx)
+}
+
+// WithTailSampling buffers the spans of each trace and only exports the traces the configuration keeps, as described by
+// NewTailSamplingProcessor. It has no effect when the spans are not exported.
+func WithTailSampling(cfg TailSamplingConfig) Option {
+	return func(s *settings) {
+		s.tailSampling = &cfg
+	}
+}
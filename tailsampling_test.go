@@ -0,0 +1,302 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jsnfwlr/go11y"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// tailSampled creates a tracer provider that tail samples the spans into the in-memory exporter
+func tailSampled(cfg go11y.TailSamplingConfig) (*otelSDKTrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := go11y.NewTailSamplingProcessor(otelSDKTrace.NewSimpleSpanProcessor(exporter), cfg)
+
+	return otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(processor)), exporter
+}
+
+func TestTailSampling(t *testing.T) {
+	start := time.Now()
+
+	testCases := []struct {
+		name     string
+		root     func(span otelTrace.Span)
+		child    func(span otelTrace.Span)
+		duration time.Duration
+		expected bool
+	}{
+		{
+			name:     "uninteresting",
+			expected: false,
+		},
+		{
+			name:     "error status",
+			child:    func(span otelTrace.Span) { span.SetStatus(codes.Error, "failed") },
+			expected: true,
+		},
+		{
+			name: "error log event",
+			child: func(span otelTrace.Span) {
+				span.AddEvent("could not connect", otelTrace.WithAttributes(attribute.Int(go11y.FieldSeverityNumber, 17)))
+			},
+			expected: true,
+		},
+		{
+			name: "warning log event",
+			child: func(span otelTrace.Span) {
+				span.AddEvent("retrying", otelTrace.WithAttributes(attribute.Int(go11y.FieldSeverityNumber, 13)))
+			},
+			expected: false,
+		},
+		{
+			name:     "slow",
+			duration: 2 * time.Second,
+			expected: true,
+		},
+		{
+			name:     "attribute",
+			root:     func(span otelTrace.Span) { span.SetAttributes(attribute.String("tenant", "acme-eu")) },
+			expected: true,
+		},
+		{
+			name:     "other attribute value",
+			root:     func(span otelTrace.Span) { span.SetAttributes(attribute.String("tenant", "globex")) },
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp, exporter := tailSampled(go11y.TailSamplingConfig{Latency: time.Second, Attributes: map[string]string{"tenant": "acme*"}})
+			defer func() { _ = tp.Shutdown(context.Background()) }()
+
+			tracer := tp.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "root", otelTrace.WithTimestamp(start))
+			if tc.root != nil {
+				tc.root(root)
+			}
+
+			_, child := tracer.Start(ctx, "child", otelTrace.WithTimestamp(start))
+			if tc.child != nil {
+				tc.child(child)
+			}
+			child.End(otelTrace.WithTimestamp(start.Add(time.Millisecond)))
+
+			if len(exporter.GetSpans()) != 0 {
+				t.Fatalf("expected the spans to be buffered until the root span ends")
+			}
+
+			root.End(otelTrace.WithTimestamp(start.Add(tc.duration)))
+
+			spans := exporter.GetSpans()
+			if tc.expected && len(spans) != 2 {
+				t.Errorf("expected the trace to be kept, got %d spans", len(spans))
+			}
+
+			if !tc.expected && len(spans) != 0 {
+				t.Errorf("expected the trace to be dropped, got %d spans", len(spans))
+			}
+
+			// a span that ends after the decision follows it
+			_, late := tracer.Start(ctx, "late")
+			late.End()
+
+			expected := len(spans)
+			if tc.expected {
+				expected++
+			}
+
+			if len(exporter.GetSpans()) != expected {
+				t.Errorf("expected the late span to follow the decision, got %d spans", len(exporter.GetSpans()))
+			}
+		})
+	}
+}
+
+func TestTailSamplingRatio(t *testing.T) {
+	tp, exporter := tailSampled(go11y.TailSamplingConfig{Ratio: 1})
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	for range 3 {
+		_, span := tp.Tracer("test").Start(context.Background(), "root")
+		span.End()
+	}
+
+	if len(exporter.GetSpans()) != 3 {
+		t.Errorf("expected every trace to be kept with a ratio of 1, got %d spans", len(exporter.GetSpans()))
+	}
+}
+
+func TestTailSamplingWindow(t *testing.T) {
+	tp, exporter := tailSampled(go11y.TailSamplingConfig{Window: 20 * time.Millisecond})
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+	defer root.End()
+
+	_, child := tp.Tracer("test").Start(ctx, "child")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(exporter.GetSpans()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(exporter.GetSpans()) != 1 {
+		t.Errorf("expected the trace to be decided when the window passed, got %d spans", len(exporter.GetSpans()))
+	}
+}
+
+func TestTailSamplingCaps(t *testing.T) {
+	tp, exporter := tailSampled(go11y.TailSamplingConfig{MaxTraces: 1, MaxSpansPerTrace: 2})
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	tracer := tp.Tracer("test")
+
+	ctx, first := tracer.Start(context.Background(), "first")
+	defer first.End()
+
+	for range 3 {
+		_, child := tracer.Start(ctx, "child")
+		child.SetStatus(codes.Error, "failed")
+		child.End()
+	}
+
+	if len(exporter.GetSpans()) != 0 {
+		t.Fatalf("expected the spans to be buffered")
+	}
+
+	// the second trace doesn't fit, so the first is decided early
+	ctx, second := tracer.Start(context.Background(), "second")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+
+	if len(exporter.GetSpans()) != 2 {
+		t.Errorf("expected the first trace to be kept with only 2 of its spans, got %d spans", len(exporter.GetSpans()))
+	}
+
+	second.End()
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	if len(exporter.GetSpans()) != 2 {
+		t.Errorf("expected the second trace to be dropped, got %d spans", len(exporter.GetSpans()))
+	}
+}
+
+func TestTailSamplingFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(
+		go11y.NewTailSamplingProcessor(otelSDKTrace.NewSimpleSpanProcessor(keepSpans{exporter}), go11y.TailSamplingConfig{}),
+	))
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+
+	_, child := tp.Tracer("test").Start(ctx, "child")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	if len(exporter.GetSpans()) != 0 {
+		t.Errorf("expected the trace in progress to stay buffered when flushing, got %d spans", len(exporter.GetSpans()))
+	}
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	if len(exporter.GetSpans()) != 1 {
+		t.Errorf("expected the trace to be decided when shutting down, got %d spans", len(exporter.GetSpans()))
+	}
+
+	root.End()
+}
+
+func TestTailSamplingDecisions(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(
+		go11y.NewTailSamplingProcessor(otelSDKTrace.NewSimpleSpanProcessor(keepSpans{exporter}), go11y.TailSamplingConfig{MaxTraces: 1}),
+	))
+
+	tracer := tp.Tracer("test")
+
+	_, kept := tracer.Start(context.Background(), "kept")
+	kept.SetStatus(codes.Error, "failed")
+	kept.End()
+
+	// only one decision is remembered, so the decision to keep the first trace is forgotten for this one
+	ctx, dropped := tracer.Start(context.Background(), "dropped")
+	_, late := tracer.Start(ctx, "late")
+	dropped.End()
+
+	late.SetStatus(codes.Error, "failed")
+	late.End()
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "kept" {
+		t.Errorf("expected the late span to follow the decision to drop its trace, got %d spans", len(spans))
+	}
+}
+
+func TestTailSamplingConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "go11y.yaml", "tracing:\n  tail_sampling:\n    window: 1m\n    latency: 2s\n    ratio: 0\n")
+
+	cfg, err := go11y.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, o, err := go11y.New(context.Background(), go11y.WithConfig(cfg), go11y.WithOutput(io.Discard), go11y.WithTraceExporter(keepSpans{exporter}))
+	if err != nil {
+		t.Fatalf("failed to create observer: %v", err)
+	}
+
+	value := o.EffectiveConfig()["tracing.tail_sampling"]
+	if !strings.Contains(value.Value, "window=1m0s latency=2s") || value.Source != go11y.SourceFile {
+		t.Errorf("expected the tail sampling settings from the file, got %+v", value)
+	}
+
+	_, ok := go11y.Span(ctx, o.Tracer("test"), "ok", go11y.SpanKindInternal)
+	ok.Info("nothing to see")
+	ok.End()
+
+	_, failed := go11y.Span(ctx, o.Tracer("test"), "failed", go11y.SpanKindInternal)
+	failed.Error("could not connect", errors.New("connection refused"), go11y.SeverityMedium)
+	failed.End()
+
+	o.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "failed" {
+		t.Errorf("expected only the trace with the error to be exported, got %v", spans)
+	}
+
+	_, err = go11y.LoadConfigFile(writeConfigFile(t, "go11y.yaml", "tracing:\n  tail_sampling:\n    window: soon\n    ratio: 2\n    max_traces: -1\n"))
+	for _, expected := range []string{"tracing.tail_sampling.window", "tracing.tail_sampling.ratio", "tracing.tail_sampling.max_traces"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error about %s, got %v", expected, err)
+		}
+	}
+}
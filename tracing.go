@@ -3,17 +3,19 @@ package go11y
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"slices"
-	"strings"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	otelAttribute "go.opentelemetry.io/otel/attribute"
-	otelExportTrace "go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	otelExportTraceHTTP "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	otelResource "go.opentelemetry.io/otel/sdk/resource"
 	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
-	otelSemConv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
@@ -21,6 +23,22 @@ func (o *Observer) Tracer(name string, opts ...otelTrace.TracerOption) otelTrace
 	return o.traceProvider.Tracer(name, opts...)
 }
 
+// UseTracerProvider shuts down the observer's trace provider and replaces it, and the global trace provider, with tp.
+// This allows the spans to be sent somewhere other than the configured OpenTelemetry URL, such as an in-memory exporter in tests.
+func (o *Observer) UseTracerProvider(ctx context.Context, tp *otelSDKTrace.TracerProvider) (fault error) {
+	if o.traceProvider != nil {
+		if err := o.traceProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down the previous trace provider: %w", err)
+		}
+	}
+
+	o.traceProvider = tp
+	o.tracingMode = TracingModeCustom
+	otel.SetTracerProvider(tp)
+
+	return nil
+}
+
 // func (o *Observer) SpanContext() otelTrace.SpanContext {
 // 	if o.activeSpan == nil {
 // 		return otelTrace.SpanContext{}
@@ -29,48 +47,163 @@ func (o *Observer) Tracer(name string, opts ...otelTrace.TracerOption) otelTrace
 // 	return o.activeSpan.SpanContext()
 // }
 
-func tracerProvider(ctx context.Context, cfg Configurator) (tracerProvider *otelSDKTrace.TracerProvider, fault error) {
-	headers := map[string]string{
-		"content-type": "application/json",
+// The ways the observer can handle the spans, from Observer.TracingMode
+const (
+	TracingModeOTLP     = "otlp"     // exported to the collector at the OpenTelemetry URL
+	TracingModeStdout   = "stdout"   // written to stdout
+	TracingModeFile     = "file"     // written to a file
+	TracingModeCustom   = "custom"   // sent to the exporter or trace provider passed to the observer
+	TracingModeLocal    = "local"    // recorded, so the logs have trace and span IDs, but not exported
+	TracingModeDisabled = "disabled" // not recorded or exported, with OTEL_SDK_DISABLED
+)
+
+// tracingMode returns how the spans are handled with the configuration and settings. The spans are only recorded
+// locally if they would be exported to the collector, but there isn't an OpenTelemetry URL.
+func tracingMode(cfg Configurator, s *settings) string {
+	switch {
+	case s.tracingDisabled:
+		return TracingModeDisabled
+	case s.exporter != nil:
+		return TracingModeCustom
+	case s.traceExporter == ExporterStdout:
+		return TracingModeStdout
+	case s.traceExporter == ExporterFile:
+		return TracingModeFile
+	case cfg.URL() == "":
+		return TracingModeLocal
+	default:
+		return TracingModeOTLP
 	}
+}
+
+// TracingMode returns how the observer handles the spans, which is one of the TracingMode constants
+func (o *Observer) TracingMode() string {
+	return o.tracingMode
+}
 
-	options := []otelExportTraceHTTP.Option{
-		otelExportTraceHTTP.WithEndpointURL(cfg.URL()),
-		otelExportTraceHTTP.WithCompression(otelExportTraceHTTP.GzipCompression),
-		otelExportTraceHTTP.WithHeaders(headers),
+// logTracingMode logs how the spans are handled, with where they are sent to, as a debug message via the observer
+func (o *Observer) logTracingMode() {
+	args := []any{FieldTracingMode, o.tracingMode}
+
+	switch o.tracingMode {
+	case TracingModeOTLP:
+		args = append(args, FieldTracingURL, withoutUserInfo(o.cfg.URL()))
+	case TracingModeFile:
+		args = append(args, FieldTracingFile, o.settings.traceFile.Path)
 	}
 
-	if !strings.HasPrefix(cfg.URL(), "https://") {
-		options = append(options, otelExportTraceHTTP.WithInsecure())
+	o.Debug("tracing configured", args...)
+}
+
+// withoutUserInfo removes the username and password from the URL, if it has them
+func withoutUserInfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
 	}
 
-	oc := otelExportTraceHTTP.NewClient(options...)
+	u.User = nil
 
-	exporter, err := otelExportTrace.New(ctx, oc)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create exporter: %w", err)
+	return u.String()
+}
+
+// tracerProvider creates the observer's trace provider, which batches the spans to the exporter from the settings (or to
+// the exporter selected by the configuration if there isn't one) and describes the service with the resource. If tracing is
+// disabled with OTEL_SDK_DISABLED, the spans are not recorded or exported, but the trace context is still propagated.
+// Otherwise the sampler decides which traces are sampled, and the tail sampling settings (if there are any) which of them
+// are exported once they end. If there is nowhere to export the spans to, they are only recorded locally, so there is no
+// network activity.
+func tracerProvider(ctx context.Context, cfg Configurator, s *settings, res *otelResource.Resource, sampler otelSDKTrace.Sampler) (tracerProvider *otelSDKTrace.TracerProvider, fault error) {
+	options := []otelSDKTrace.TracerProviderOption{
+		otelSDKTrace.WithResource(res),
 	}
 
-	randy := otelSDKTrace.NewTracerProvider(
-		otelSDKTrace.WithBatcher(
+	switch tracingMode(cfg, s) {
+	case TracingModeDisabled:
+		options = append(options, otelSDKTrace.WithSampler(otelSDKTrace.NeverSample()))
+	case TracingModeLocal:
+		// without a span processor the spans are never exported, but they still have IDs for the logs
+		options = append(options, otelSDKTrace.WithSampler(sampler))
+	default:
+		exporter := s.exporter
+		if exporter == nil {
+			var err error
+
+			exporter, err = spanExporter(ctx, cfg, s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create exporter: %w", err)
+			}
+		}
+
+		processor := otelSDKTrace.NewBatchSpanProcessor(
 			exporter,
 			otelSDKTrace.WithMaxExportBatchSize(otelSDKTrace.DefaultMaxExportBatchSize),
 			otelSDKTrace.WithBatchTimeout(otelSDKTrace.DefaultScheduleDelay*time.Millisecond),
 			otelSDKTrace.WithMaxExportBatchSize(otelSDKTrace.DefaultMaxExportBatchSize),
-		),
-		otelSDKTrace.WithResource(
-			otelResource.NewWithAttributes(
-				otelSemConv.SchemaURL,
-				otelSemConv.ServiceNameKey.String(cfg.ServiceName()),
-			),
-		),
-	)
+		)
+
+		if s.tailSampling != nil {
+			processor = NewTailSamplingProcessor(processor, *s.tailSampling)
+		}
+
+		options = append(options, otelSDKTrace.WithSpanProcessor(processor))
+
+		options = append(options, otelSDKTrace.WithSampler(sampler))
+	}
+
+	randy := otelSDKTrace.NewTracerProvider(options...)
 
 	otel.SetTracerProvider(randy)
 
 	return randy, nil
 }
 
+// propagator returns the propagator from the settings, or the W3C trace context and baggage propagators if there isn't one
+func propagator(s *settings) propagation.TextMapPropagator {
+	if s.propagator != nil {
+		return s.propagator
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// recordHTTPStatus sets the http.response.status_code of the span, and its error.type and an error status if the request
+// failed, following the HTTP semantic conventions: a server span fails with a 5xx status, and a client span with a 4xx
+// or 5xx status or an error instead of a response. Nothing is recorded unless the span is of the kind, so a roundtripper
+// called inside LogRequest doesn't record the downstream response on the server's span.
+func recordHTTPStatus(span otelTrace.Span, kind otelTrace.SpanKind, statusCode int, err error) {
+	if ro, ok := span.(otelSDKTrace.ReadOnlySpan); !ok || ro.SpanKind() != kind || !span.IsRecording() {
+		return
+	}
+
+	if err != nil {
+		span.SetAttributes(otelSemConv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+		span.SetStatus(codes.Error, "")
+
+		return
+	}
+
+	span.SetAttributes(otelSemConv.HTTPResponseStatusCodeKey.Int(statusCode))
+
+	if statusCode >= 500 || (statusCode >= 400 && kind == otelTrace.SpanKindClient) {
+		span.SetAttributes(otelSemConv.ErrorTypeKey.String(strconv.Itoa(statusCode)))
+		span.SetStatus(codes.Error, "")
+	}
+}
+
+// levelEventOption adds the name and OpenTelemetry severity of the level to a span event
+func levelEventOption(level slog.Level) otelTrace.EventOption {
+	return otelTrace.WithAttributes(
+		otelAttribute.String(FieldSeverityText, LevelName(level)),
+		otelAttribute.Int(FieldSeverityNumber, int(LevelSeverity(level))),
+	)
+}
+
+// attributes redacts the args and converts them to span attributes
+func (o *Observer) attributes(args ...any) []otelAttribute.KeyValue {
+	return argsToAttributes(o.redactor.RedactArgs(args...)...)
+}
+
 func argsToAttributes(combinedArgs ...any) []otelAttribute.KeyValue {
 	if len(combinedArgs) == 0 {
 		return nil